@@ -0,0 +1,270 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MCPGatewayRoutingMode defines how the gateway exposes the selected MCP servers.
+type MCPGatewayRoutingMode string
+
+const (
+	// MCPGatewayRoutingModePath exposes every server on its own path,
+	// /<server-name>/mcp and /<server-name>/sse. This is the default.
+	MCPGatewayRoutingModePath MCPGatewayRoutingMode = "Path"
+
+	// MCPGatewayRoutingModeFederated exposes the tools of all servers behind a single
+	// /mcp and /sse endpoint. Tool names are prefixed with the name of their server.
+	MCPGatewayRoutingModeFederated MCPGatewayRoutingMode = "Federated"
+)
+
+// MCPGatewayConditionType represents the condition types for MCPGateway status.
+type MCPGatewayConditionType string
+
+const (
+	// MCPGatewayConditionAccepted indicates that the MCPGateway configuration is valid.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Accepted"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "InvalidConfig"
+	MCPGatewayConditionAccepted MCPGatewayConditionType = "Accepted"
+
+	// MCPGatewayConditionProgrammed indicates that the gateway Deployment, Service
+	// and ConfigMap have been created and configured.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Programmed"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "DeploymentFailed"
+	MCPGatewayConditionProgrammed MCPGatewayConditionType = "Programmed"
+
+	// MCPGatewayConditionReady indicates that the gateway has running pods that are
+	// ready to accept connections.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Available"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "PodsNotReady"
+	// * "NotAvailable"
+	MCPGatewayConditionReady MCPGatewayConditionType = "Ready"
+)
+
+// MCPGatewayConditionReason represents the reasons for MCPGateway conditions.
+type MCPGatewayConditionReason string
+
+const (
+	// Accepted condition reasons
+	MCPGatewayReasonAccepted      MCPGatewayConditionReason = "Accepted"
+	MCPGatewayReasonInvalidConfig MCPGatewayConditionReason = "InvalidConfig"
+
+	// Programmed condition reasons
+	MCPGatewayReasonProgrammed       MCPGatewayConditionReason = "Programmed"
+	MCPGatewayReasonDeploymentFailed MCPGatewayConditionReason = "DeploymentFailed"
+
+	// Ready condition reasons
+	MCPGatewayReasonAvailable    MCPGatewayConditionReason = "Available"
+	MCPGatewayReasonPodsNotReady MCPGatewayConditionReason = "PodsNotReady"
+	MCPGatewayReasonNotAvailable MCPGatewayConditionReason = "NotAvailable"
+)
+
+// MCPGatewaySpec defines the desired state of MCPGateway.
+type MCPGatewaySpec struct {
+	// Selector selects the MCPServers in the namespace of the MCPGateway that are
+	// served by the gateway. An empty selector selects all MCPServers in the namespace.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// RoutingMode defines how the selected MCP servers are exposed.
+	// +optional
+	// +kubebuilder:validation:Enum=Path;Federated
+	// +kubebuilder:default=Path
+	RoutingMode MCPGatewayRoutingMode `json:"routingMode,omitempty"`
+
+	// Authentication defines how clients of the gateway are authenticated.
+	// +optional
+	Authentication *MCPGatewayAuthentication `json:"authentication,omitempty"`
+
+	// RateLimit limits the requests accepted by every gateway replica.
+	// +optional
+	RateLimit *MCPGatewayRateLimit `json:"rateLimit,omitempty"`
+
+	// Deployment defines how the gateway is deployed.
+	// +optional
+	Deployment MCPGatewayDeployment `json:"deployment,omitempty"`
+}
+
+// MCPGatewayAuthentication defines how clients of the gateway are authenticated.
+type MCPGatewayAuthentication struct {
+	// JWT requires clients to present a bearer JWT signed by the given issuer.
+	// +optional
+	JWT *JWTAuthentication `json:"jwt,omitempty"`
+}
+
+// JWTAuthentication validates bearer JWTs presented by clients.
+// +kubebuilder:validation:XValidation:rule="has(self.jwksURL) != has(self.jwksConfigMapRef)",message="exactly one of jwksURL and jwksConfigMapRef must be set"
+type JWTAuthentication struct {
+	// Issuer is the expected iss claim of the tokens.
+	// +kubebuilder:validation:MinLength=1
+	Issuer string `json:"issuer"`
+
+	// Audiences are the accepted aud claims of the tokens. If empty, the audience is not checked.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+
+	// JWKSURL is the URL of the JSON Web Key Set used to verify the tokens.
+	// +optional
+	JWKSURL string `json:"jwksURL,omitempty"`
+
+	// JWKSConfigMapRef references a key of a ConfigMap in the namespace of the
+	// MCPGateway holding the JSON Web Key Set used to verify the tokens.
+	// +optional
+	JWKSConfigMapRef *corev1.ConfigMapKeySelector `json:"jwksConfigMapRef,omitempty"`
+}
+
+// MCPGatewayRateLimit is a token bucket applied to the requests of all clients.
+type MCPGatewayRateLimit struct {
+	// MaxTokens is the size of the bucket, i.e. the maximum burst of requests.
+	// +kubebuilder:validation:Minimum=1
+	MaxTokens uint64 `json:"maxTokens"`
+
+	// TokensPerFill is the number of tokens added to the bucket on every fill.
+	// +kubebuilder:validation:Minimum=1
+	TokensPerFill uint64 `json:"tokensPerFill"`
+
+	// FillInterval is the interval at which the bucket is refilled.
+	FillInterval metav1.Duration `json:"fillInterval"`
+}
+
+// MCPGatewayDeployment defines how the gateway is deployed.
+type MCPGatewayDeployment struct {
+	// Replicas defines the number of gateway replicas.
+	// +optional
+	// +kubebuilder:default=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Image overrides the agentgateway image of the gateway.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ImagePullPolicy defines the pull policy for the gateway image.
+	// +optional
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Port defines the port on which the gateway listens.
+	// +optional
+	// +kubebuilder:default=3000
+	Port uint16 `json:"port,omitempty"`
+
+	// Resources defines the compute resource requirements of the gateway container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// SecurityContext defines the security context of the gateway container.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// NodeSelector is a selector which must be true for the gateway pods to fit on a node.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations allow the gateway pods to schedule onto nodes with matching taints.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// MCPGatewayServerStatus describes an MCPServer attached to the gateway.
+type MCPGatewayServerStatus struct {
+	// Name is the name of the MCPServer.
+	Name string `json:"name"`
+
+	// Ready reports whether the MCPServer Ready condition is True.
+	Ready bool `json:"ready"`
+
+	// Message is the message of the MCPServer Ready condition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MCPGatewayStatus defines the observed state of MCPGateway.
+type MCPGatewayStatus struct {
+	// Conditions describe the current conditions of the MCPGateway.
+	//
+	// Known condition types are:
+	//
+	// * "Accepted"
+	// * "Programmed"
+	// * "Ready"
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed for this MCPGateway.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Servers lists the MCPServers attached to the gateway, sorted by name.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Servers []MCPGatewayServerStatus `json:"servers,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mcpgw
+// +kubebuilder:printcolumn:name="Mode",type="string",JSONPath=".spec.routingMode"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:categories=kagent
+
+// MCPGateway is the Schema for the mcpgateways API. An MCPGateway runs a single
+// gateway in its namespace that routes to the selected MCPServers.
+type MCPGateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MCPGatewaySpec   `json:"spec,omitempty"`
+	Status MCPGatewayStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MCPGatewayList contains a list of MCPGateway.
+type MCPGatewayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MCPGateway `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MCPGateway{}, &MCPGatewayList{})
+}
@@ -18,7 +18,36 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// MCPServerRollbackAnnotation requests the rollback of the MCPServer to the revision
+	// whose number it holds, or to its last good revision when it is empty or 0. The
+	// controller removes the annotation once the rollback is made.
+	MCPServerRollbackAnnotation = "mcpserver.kagent.dev/rollback-to-revision"
+
+	// MCPServerNameLabel labels the ControllerRevisions and the pods of an MCPServer with
+	// its name. It is set by the controller and overrides deployment.labels, so that only
+	// the pods of MCPServers are mapped back to them.
+	MCPServerNameLabel = "mcpserver.kagent.dev/name"
+
+	// MCPServerBindingLabel labels the Secrets holding the connection information of
+	// MCPServers, which are the only Secrets watched by the controller.
+	MCPServerBindingLabel = "mcpserver.kagent.dev/binding"
+
+	// MCPServerApproveToolsAnnotation approves the changed tools of the MCPServer. It
+	// holds the digest of the changed tools reported by the ToolsChanged condition, so
+	// that a later change is not approved by mistake. The controller removes the
+	// annotation once the tools are pinned.
+	MCPServerApproveToolsAnnotation = "mcpserver.kagent.dev/approve-tools"
+
+	// ShardLabel assigns an MCPServer or MCPGateway to the controller shard whose number
+	// it holds, when the controller is sharded. Resources without it, or with a number
+	// that is not a shard, are assigned to a shard by the hash of their namespace and name.
+	ShardLabel = "kmcp.kagent.dev/shard"
 )
 
 // MCPServerTransportType defines the type of transport for the MCP server.
@@ -32,6 +61,18 @@ const (
 	TransportTypeHTTP TransportType = "http"
 )
 
+// WorkloadKind defines the kind of workload used to run the MCP server.
+type WorkloadKind string
+
+const (
+	// WorkloadKindDeployment runs the MCP server as a Deployment. This is the default.
+	WorkloadKindDeployment WorkloadKind = "Deployment"
+
+	// WorkloadKindStatefulSet runs the MCP server as a StatefulSet, which allows
+	// each replica to keep its own persistent volumes across rollouts.
+	WorkloadKindStatefulSet WorkloadKind = "StatefulSet"
+)
+
 // MCPServerConditionType represents the condition types for MCPServer status.
 type MCPServerConditionType string
 
@@ -106,6 +147,22 @@ const (
 	// but should prefer to use the reasons listed above to improve
 	// interoperability.
 	MCPServerConditionReady MCPServerConditionType = "Ready"
+
+	// MCPServerConditionToolsChanged indicates that the tools served by the MCPServer
+	// differ from its pinned tools. It is only set when toolPinning is configured.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "ToolsChanged"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "ToolsPinned"
+	//
+	// Controllers may raise this condition with other reasons,
+	// but should prefer to use the reasons listed above to improve
+	// interoperability.
+	MCPServerConditionToolsChanged MCPServerConditionType = "ToolsChanged"
 )
 
 // MCPServerConditionReason represents the reasons for MCPServer conditions.
@@ -116,6 +173,7 @@ const (
 	MCPServerReasonAccepted             MCPServerConditionReason = "Accepted"
 	MCPServerReasonInvalidConfig        MCPServerConditionReason = "InvalidConfig"
 	MCPServerReasonUnsupportedTransport MCPServerConditionReason = "UnsupportedTransport"
+	MCPServerReasonPolicyViolation      MCPServerConditionReason = "PolicyViolation"
 
 	// ResolvedRefs condition reasons
 	MCPServerReasonResolvedRefs  MCPServerConditionReason = "ResolvedRefs"
@@ -126,17 +184,26 @@ const (
 	MCPServerReasonDeploymentFailed MCPServerConditionReason = "DeploymentFailed"
 	MCPServerReasonServiceFailed    MCPServerConditionReason = "ServiceFailed"
 	MCPServerReasonConfigMapFailed  MCPServerConditionReason = "ConfigMapFailed"
+	MCPServerReasonRolledBack       MCPServerConditionReason = "RolledBack"
 
 	// Ready condition reasons
 	MCPServerReasonReady        MCPServerConditionReason = "Ready"
 	MCPServerReasonPodsNotReady MCPServerConditionReason = "PodsNotReady"
 	MCPServerReasonAvailable    MCPServerConditionReason = "Available"
 	MCPServerReasonNotAvailable MCPServerConditionReason = "NotAvailable"
+
+	// ToolsChanged condition reasons
+	MCPServerReasonToolsChanged MCPServerConditionReason = "ToolsChanged"
+	MCPServerReasonToolsPinned  MCPServerConditionReason = "ToolsPinned"
 )
 
 // MCPServerSpec defines the desired state of MCPServer.
+// +kubebuilder:validation:XValidation:rule="!has(self.parameters) || has(self.templateRef)",message="parameters requires templateRef"
+// +kubebuilder:validation:XValidation:rule="has(self.templateRef) || has(self.deployment)",message="deployment is required unless templateRef is set"
 type MCPServerSpec struct {
-	// Configuration to Deploy the MCP Server using a docker container
+	// Configuration to Deploy the MCP Server using a docker container.
+	// Required unless templateRef is set.
+	// +optional
 	Deployment MCPServerDeployment `json:"deployment"`
 
 	// TransportType defines the type of mcp server being run
@@ -159,10 +226,372 @@ type MCPServerSpec struct {
 	// +optional
 	// +kubebuilder:default="30s"
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Service configures the Service that fronts the MCP server.
+	// +optional
+	Service *MCPServerService `json:"service,omitempty"`
+
+	// Observability configures the telemetry emitted by the transport adapter.
+	// +optional
+	Observability *Observability `json:"observability,omitempty"`
+
+	// SecurityProfile hardens the pods of the MCP server. The profile fills the
+	// security settings that are not set in the deployment security contexts and
+	// rejects settings that violate it. Defaults to the profile configured on the
+	// controller; no profile is applied when neither is set.
+	// - baseline: seccomp RuntimeDefault, no privileged containers, no privilege
+	//   escalation and only the default container capabilities.
+	// - restricted: baseline, plus non-root users, all capabilities dropped and a
+	//   read-only root filesystem with writable emptyDirs for /tmp and the home
+	//   directory used by the npm and uv caches.
+	// - sandboxed: restricted, plus the sandboxed container runtime class
+	//   configured on the controller, such as gVisor.
+	// The deployment.podTemplate overlay is applied after the profile.
+	// +optional
+	// +kubebuilder:validation:Enum=baseline;restricted;sandboxed
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+
+	// ClassName is the name of the MCPServerClass whose defaults and policy apply to
+	// the MCPServer. Defaults to the class annotated as the default class, if any.
+	// +optional
+	ClassName string `json:"className,omitempty"`
+
+	// TemplateRef instantiates the MCPServer from an MCPServerTemplate. The spec of
+	// the template, expanded with the parameters, replaces the spec of the MCPServer,
+	// except for className, the rollback settings and toolPinning which are kept when
+	// set. Changes to the template roll out to all of its instances.
+	// +optional
+	TemplateRef *MCPServerTemplateReference `json:"templateRef,omitempty"`
+
+	// Parameters are the values of the parameters of the template, keyed by name.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// RollbackOnFailure reverts the MCPServer to its last good revision when a new
+	// revision does not become ready within the progress deadline. The failed revision
+	// is not rolled out again until the spec changes.
+	// +optional
+	RollbackOnFailure *RollbackOnFailure `json:"rollbackOnFailure,omitempty"`
+
+	// RevisionHistoryLimit is the number of old revisions of the MCPServer kept as
+	// ControllerRevisions to roll back to. Defaults to 10.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// ToolPinning records a hash of the name, description and input schema of each
+	// tool of the MCPServer after its first successful rollout, and compares the tools
+	// of later rollouts to it. This detects MCP packages changing their tools to inject
+	// instructions into agents.
+	// +optional
+	ToolPinning *ToolPinning `json:"toolPinning,omitempty"`
 }
 
+// MCPServerService configures the Service of the MCPServer.
+// +kubebuilder:validation:XValidation:rule="!has(self.externalTrafficPolicy) || (has(self.type) && self.type != 'ClusterIP')",message="externalTrafficPolicy requires type NodePort or LoadBalancer"
+// +kubebuilder:validation:XValidation:rule="!has(self.headless) || !self.headless || !has(self.type) || self.type == 'ClusterIP'",message="headless requires type ClusterIP"
+type MCPServerService struct {
+	// Type is the type of the Service. Defaults to ClusterIP.
+	// +optional
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Headless creates the Service without a cluster IP, so that clients resolve the
+	// addresses of the pods and can route the requests of a session to the same pod.
+	// The cluster IP of an existing Service cannot be changed: the Service must be
+	// deleted to switch between headless and not headless.
+	// +optional
+	Headless bool `json:"headless,omitempty"`
+
+	// Annotations are added to the Service, for example the annotations configuring
+	// the load balancer of a cloud provider.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels are added to the Service. They cannot override the labels selecting the
+	// pods of the MCP server.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Ports are additional ports of the Service, for example the ports of sidecar
+	// containers. Each port needs a name that differs from the http and metrics ports.
+	// +optional
+	Ports []corev1.ServicePort `json:"ports,omitempty"`
+
+	// ExternalTrafficPolicy defines how the Service routes external traffic. Local
+	// keeps the source IP of clients. Requires type NodePort or LoadBalancer.
+	// +optional
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// AppProtocol overrides the appProtocol of the http port, which defaults to the
+	// protocol routing the Service as an MCP backend in kgateway. An empty value
+	// removes the appProtocol.
+	// +optional
+	AppProtocol *string `json:"appProtocol,omitempty"`
+}
+
+// RollbackOnFailure configures the automatic rollback of failed revisions.
+type RollbackOnFailure struct {
+	// ProgressDeadline is the time a new revision has to become ready before it is
+	// rolled back. Defaults to 10m.
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+// ToolPinningPolicy defines how changed tools are handled.
+type ToolPinningPolicy string
+
+const (
+	// ToolPinningPolicyWarn reports the changed tools with an Event and the
+	// ToolsChanged condition.
+	ToolPinningPolicyWarn ToolPinningPolicy = "warn"
+
+	// ToolPinningPolicyBlock also keeps a revision serving changed tools from being
+	// rolled out, and scales down a workload whose tools changed on a restart, until the
+	// changed tools are approved.
+	ToolPinningPolicyBlock ToolPinningPolicy = "block"
+)
+
+// ToolPinning configures the pinning of the tools of an MCPServer.
+type ToolPinning struct {
+	// Policy defines how changed tools are handled. With block, a new revision is first
+	// deployed as a single replica canary while the workload keeps serving the last good
+	// revision, and only rolled out once the tools of the canary match the pinned tools.
+	// The tools of the workload are listed again when its pods restart and at the
+	// toolsRecheck interval of the controller, since a package that is not pinned to a
+	// version may change without a new revision: with block, the workload is then scaled
+	// down. Changed tools are approved with the mcpserver.kagent.dev/approve-tools
+	// annotation.
+	// +optional
+	// +kubebuilder:default=warn
+	// +kubebuilder:validation:Enum=warn;block
+	Policy ToolPinningPolicy `json:"policy,omitempty"`
+}
+
+// ToolHash is the hash of the name, description and input schema of a tool.
+type ToolHash struct {
+	// Name is the name of the tool.
+	Name string `json:"name"`
+
+	// Hash is the hex encoded SHA-256 hash of the tool.
+	Hash string `json:"hash"`
+}
+
+// MCPServerTemplateReference references an MCPServerTemplate.
+type MCPServerTemplateReference struct {
+	// Name is the name of the MCPServerTemplate.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// SecurityProfile defines the hardening applied to the pods of an MCP server.
+type SecurityProfile string
+
+const (
+	// SecurityProfileBaseline prevents known privilege escalations.
+	SecurityProfileBaseline SecurityProfile = "baseline"
+
+	// SecurityProfileRestricted follows the Kubernetes restricted pod security standard
+	// and makes the root filesystem read-only.
+	SecurityProfileRestricted SecurityProfile = "restricted"
+
+	// SecurityProfileSandboxed runs the restricted pods in a sandboxed container runtime.
+	SecurityProfileSandboxed SecurityProfile = "sandboxed"
+)
+
+// PackageCacheType defines the volume backing the package cache.
+type PackageCacheType string
+
+const (
+	// PackageCacheTypeEmptyDir keeps the cache in an emptyDir of each pod.
+	PackageCacheTypeEmptyDir PackageCacheType = "EmptyDir"
+
+	// PackageCacheTypePersistentVolumeClaim keeps the cache in an existing
+	// PersistentVolumeClaim shared by the pods.
+	PackageCacheTypePersistentVolumeClaim PackageCacheType = "PersistentVolumeClaim"
+)
+
+// PackageCache configures the package cache of npx and uvx MCP servers.
+// +kubebuilder:validation:XValidation:rule="self.type != 'PersistentVolumeClaim' || has(self.claimName)",message="claimName is required when type is 'PersistentVolumeClaim'"
+type PackageCache struct {
+	// Type is the volume backing the cache. An EmptyDir cache is only useful with
+	// preInstall, which fills it before the server starts.
+	// +optional
+	// +kubebuilder:default=EmptyDir
+	// +kubebuilder:validation:Enum=EmptyDir;PersistentVolumeClaim
+	Type PackageCacheType `json:"type,omitempty"`
+
+	// ClaimName is the name of the PersistentVolumeClaim holding the cache. The
+	// claim must be ReadWriteMany when the MCP server runs several replicas.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// SizeLimit limits the size of an EmptyDir cache.
+	// +optional
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+
+	// PreInstall installs the package in an init container before the MCP server
+	// starts. The MCP server then runs the installed version without contacting
+	// the registry, which pins the version resolved by the init container for as
+	// long as the cache is kept. The package is the first positional argument of
+	// npx or uvx, or the value of their --package or --from flag.
+	// +optional
+	PreInstall bool `json:"preInstall,omitempty"`
+
+	// RegistrySecretRef references a Secret with the registry settings used to
+	// install packages. The .npmrc key is used as the npm user config and the other
+	// keys, such as UV_INDEX_URL, HTTPS_PROXY or NO_PROXY, are set as environment
+	// variables.
+	// +optional
+	RegistrySecretRef *corev1.LocalObjectReference `json:"registrySecretRef,omitempty"`
+}
+
+// Probes configures the probes of the container serving MCP traffic.
+type Probes struct {
+	// Disabled removes all the probes, including the defaults.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Liveness overrides the default liveness probe, which checks that the MCP
+	// port accepts connections.
+	// +optional
+	Liveness *corev1.Probe `json:"liveness,omitempty"`
+
+	// Readiness overrides the default readiness probe. For stdio MCP servers it
+	// checks the health endpoint of the transport adapter; for HTTP MCP servers it
+	// sends a GET request to the readiness path.
+	// +optional
+	Readiness *corev1.Probe `json:"readiness,omitempty"`
+
+	// ReadinessPath is the path checked by the default readiness probe of HTTP MCP
+	// servers. Defaults to the target path of the HTTP transport. Servers whose MCP
+	// endpoint does not answer GET requests with a success status should set it to
+	// their health endpoint.
+	// +optional
+	ReadinessPath string `json:"readinessPath,omitempty"`
+
+	// Startup overrides the default startup probe of HTTP MCP servers, which
+	// tolerates slow package installs by npx and uvx for up to five minutes. Stdio
+	// MCP servers have no default startup probe, since the transport adapter
+	// serves its port before the MCP server process is started.
+	// +optional
+	Startup *corev1.Probe `json:"startup,omitempty"`
+}
+
+// MetricsMonitorKind defines the Prometheus Operator resource that scrapes the metrics.
+type MetricsMonitorKind string
+
+const (
+	// MetricsMonitorKindServiceMonitor scrapes the metrics port of the MCPServer Service.
+	MetricsMonitorKindServiceMonitor MetricsMonitorKind = "ServiceMonitor"
+
+	// MetricsMonitorKindPodMonitor scrapes the metrics port of the MCPServer pods.
+	MetricsMonitorKindPodMonitor MetricsMonitorKind = "PodMonitor"
+
+	// MetricsMonitorKindNone only exposes the metrics port.
+	MetricsMonitorKindNone MetricsMonitorKind = "None"
+)
+
+// Observability configures the telemetry emitted by the transport adapter.
+type Observability struct {
+	// Tracing configures the MCP server container to export its own spans through the
+	// standard OTEL_* environment variables. Stdio MCP servers using the agentgateway
+	// adapter backend also export the spans of the requests handled by the adapter.
+	// +optional
+	Tracing *Tracing `json:"tracing,omitempty"`
+
+	// Metrics exposes the Prometheus metrics of the transport adapter on the
+	// MCPServer Service. Metrics are only supported for stdio MCP servers using
+	// the agentgateway adapter backend.
+	// +optional
+	Metrics *Metrics `json:"metrics,omitempty"`
+}
+
+// Metrics configures the Prometheus metrics of the transport adapter.
+type Metrics struct {
+	// Port is the port the transport adapter serves its metrics on at /metrics.
+	// +optional
+	// +kubebuilder:default=15020
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port uint16 `json:"port,omitempty"`
+
+	// Monitor is the Prometheus Operator resource created to scrape the metrics.
+	// The resource is only created when its CRD is installed in the cluster.
+	// +optional
+	// +kubebuilder:default=ServiceMonitor
+	// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor;None
+	Monitor MetricsMonitorKind `json:"monitor,omitempty"`
+
+	// Interval is the scrape interval, for example 30s. Defaults to the scrape
+	// interval of the Prometheus instance.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$`
+	Interval string `json:"interval,omitempty"`
+}
+
+// Tracing configures OpenTelemetry tracing.
+type Tracing struct {
+	// OTLPEndpoint is the gRPC endpoint of the OpenTelemetry collector receiving
+	// the traces, for example http://otel-collector.observability:4317.
+	// +kubebuilder:validation:MinLength=1
+	OTLPEndpoint string `json:"otlpEndpoint"`
+
+	// SamplingPercentage is the percentage of new traces that are sampled.
+	// Traces started by a caller keep the sampling decision of the caller.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=100
+	SamplingPercentage *int32 `json:"samplingPercentage,omitempty"`
+
+	// ResourceAttributes are added to the OpenTelemetry resource of the transport
+	// adapter and the MCP server, for example deployment.environment.
+	// +optional
+	ResourceAttributes map[string]string `json:"resourceAttributes,omitempty"`
+}
+
+// StdioAdapterMode defines how the transport adapter is attached to a stdio MCP server.
+type StdioAdapterMode string
+
+const (
+	// StdioAdapterModeCopyBinary copies the transport adapter binary into the MCP server
+	// container with an init container and runs it as the container command. This is the default.
+	StdioAdapterModeCopyBinary StdioAdapterMode = "CopyBinary"
+
+	// StdioAdapterModeSidecar runs the transport adapter in a separate sidecar container.
+	// The adapter reaches the stdio server through an exec bridge on a shared volume,
+	// so nothing is executed from the MCP server image except its own command.
+	StdioAdapterModeSidecar StdioAdapterMode = "Sidecar"
+)
+
 // StdioTransport defines the configuration for a standard input/output transport.
-type StdioTransport struct{}
+type StdioTransport struct {
+	// AdapterMode defines how the transport adapter is attached to the MCP server.
+	// Use Sidecar for images that cannot run the copied adapter binary, for example
+	// images built for another architecture, noexec filesystems or read-only root
+	// filesystem policies. Sidecar mode requires deployment.cmd to be set and the
+	// MCP server image to provide a POSIX shell at /bin/sh.
+	// +optional
+	// +kubebuilder:validation:Enum=CopyBinary;Sidecar
+	// +kubebuilder:default=CopyBinary
+	AdapterMode StdioAdapterMode `json:"adapterMode,omitempty"`
+
+	// BridgeImage defines the image of the transport adapter sidecar container in
+	// Sidecar mode. The image must provide a POSIX shell together with mkfifo, mktemp
+	// and cat; the adapter binary is copied into it by the init container. It is
+	// ignored by adapter backends that run from their own image.
+	// +optional
+	BridgeImage string `json:"bridgeImage,omitempty"`
+
+	// AdapterBackend selects the stdio-to-HTTP bridge that serves the MCP server,
+	// overriding the default of the controller. The mcp-proxy backend runs from its
+	// own image and only supports the Sidecar adapter mode.
+	// +optional
+	// +kubebuilder:validation:Enum=agentgateway;mcp-proxy
+	AdapterBackend string `json:"adapterBackend,omitempty"`
+}
 
 // HTTPTransport defines the configuration for a Streamable HTTP transport.
 type HTTPTransport struct {
@@ -208,6 +637,7 @@ type MCPServerStatus struct {
 	// * "ResolvedRefs"
 	// * "Programmed"
 	// * "Ready"
+	// * "ToolsChanged"
 	//
 	// +optional
 	// +listType=map
@@ -219,11 +649,108 @@ type MCPServerStatus struct {
 	// It corresponds to the MCPServer's generation, which is updated on mutation by the API Server.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ClassName is the name of the MCPServerClass applied to the MCPServer.
+	// +optional
+	ClassName string `json:"className,omitempty"`
+
+	// TemplateGeneration is the generation of the MCPServerTemplate the MCPServer was
+	// last expanded from.
+	// +optional
+	TemplateGeneration int64 `json:"templateGeneration,omitempty"`
+
+	// CurrentRevision is the name of the ControllerRevision deployed for the MCPServer.
+	// +optional
+	CurrentRevision string `json:"currentRevision,omitempty"`
+
+	// LastGoodRevision is the name of the last ControllerRevision that became ready.
+	// +optional
+	LastGoodRevision string `json:"lastGoodRevision,omitempty"`
+
+	// RolledBackRevision is the ControllerRevision of the spec of the MCPServer when it
+	// was rolled back from it. That revision is not rolled out again until the spec changes.
+	// +optional
+	RolledBackRevision string `json:"rolledBackRevision,omitempty"`
+
+	// RolloutStartTime is the time the current revision started rolling out.
+	// +optional
+	RolloutStartTime *metav1.Time `json:"rolloutStartTime,omitempty"`
+
+	// PinnedTools are the hashes of the approved tools of the MCPServer.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	PinnedTools []ToolHash `json:"pinnedTools,omitempty"`
+
+	// ChangedTools are the hashes of the tools served by the MCPServer when they differ
+	// from the pinned tools. They replace the pinned tools once approved.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ChangedTools []ToolHash `json:"changedTools,omitempty"`
+
+	// ToolsCheckedRevision is the revision whose tools were last compared to the pinned
+	// tools.
+	// +optional
+	ToolsCheckedRevision string `json:"toolsCheckedRevision,omitempty"`
+
+	// ToolsCanaryRevision is the revision deployed as a canary, whose tools are compared
+	// to the pinned tools before it is rolled out.
+	// +optional
+	ToolsCanaryRevision string `json:"toolsCanaryRevision,omitempty"`
+
+	// ToolsCheckTime is the time the tools of the MCPServer were last listed.
+	// +optional
+	ToolsCheckTime *metav1.Time `json:"toolsCheckTime,omitempty"`
+
+	// URL is the address of the MCP endpoint of the MCPServer, the Streamable HTTP
+	// endpoint unless the MCPServer only serves HTTP+SSE.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Endpoints are the addresses of the MCP endpoints of the MCPServer.
+	// +optional
+	Endpoints *MCPServerEndpoints `json:"endpoints,omitempty"`
+
+	// Binding references the Secret holding the connection information of the
+	// MCPServer, following the Service Binding specification, so that workloads can
+	// project it.
+	// +optional
+	Binding *corev1.LocalObjectReference `json:"binding,omitempty"`
+}
+
+// MCPServerEndpoints are the addresses of the MCP endpoints of an MCPServer.
+type MCPServerEndpoints struct {
+	// StreamableHTTP is the address of the Streamable HTTP endpoint, when the
+	// MCPServer serves one.
+	// +optional
+	StreamableHTTP string `json:"streamableHttp,omitempty"`
+
+	// SSE is the address of the deprecated HTTP+SSE endpoint, when the MCPServer
+	// serves one.
+	// +optional
+	SSE string `json:"sse,omitempty"`
 }
 
 // MCPServerDeployment
 // +kubebuilder:validation:XValidation:rule="!(has(self.serviceAccount) && has(self.serviceAccountName))",message="serviceAccount and serviceAccountName are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.volumeClaimTemplates) || (has(self.workloadKind) && self.workloadKind == 'StatefulSet')",message="volumeClaimTemplates requires workloadKind StatefulSet"
 type MCPServerDeployment struct {
+	// WorkloadKind defines the kind of workload used to run the MCP server.
+	// Use StatefulSet for servers that keep state on disk, such as memory stores,
+	// SQLite-backed knowledge bases or git workspaces, so that their data
+	// survives rollouts.
+	// +optional
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	// +kubebuilder:default=Deployment
+	WorkloadKind WorkloadKind `json:"workloadKind,omitempty"`
+
+	// VolumeClaimTemplates defines the persistent volume claims created for each
+	// replica when WorkloadKind is StatefulSet. Mount them in the MCP server
+	// container by referencing the claim name in VolumeMounts.
+	// +optional
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+
 	// Image defines the container image to to deploy the MCP server.
 	// +optional
 	Image string `json:"image,omitempty"`
@@ -250,6 +777,18 @@ type MCPServerDeployment struct {
 	// +optional
 	Env map[string]string `json:"env,omitempty"`
 
+	// EnvVars defines environment variables of the MCP server container that can be
+	// sourced from Secret or ConfigMap keys and the downward API through valueFrom.
+	// A variable must not be defined in both env and envVars. The stdio process of
+	// a stdio MCP server inherits the resolved values from the container.
+	// +optional
+	EnvVars []corev1.EnvVar `json:"envVars,omitempty"`
+
+	// EnvFrom populates environment variables of the MCP server container from all
+	// the keys of ConfigMaps and Secrets.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
 	// SecretRefs defines the list of Kubernetes secrets to reference.
 	// These secrets will be mounted as volumes to the MCP server container.
 	// +optional
@@ -272,6 +811,8 @@ type MCPServerDeployment struct {
 
 	// InitContainer defines the configuration for the init container that copies
 	// the transport adapter binary. This is used for stdio transport type.
+	// In Sidecar adapter mode, the resources and security context also apply to
+	// the transport adapter sidecar container.
 	// +optional
 	InitContainer *InitContainerConfig `json:"initContainer,omitempty"`
 
@@ -321,6 +862,40 @@ type MCPServerDeployment struct {
 	// +optional
 	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
 
+	// Probes configures the liveness, readiness and startup probes of the container
+	// serving MCP traffic: the transport adapter for stdio MCP servers and the MCP
+	// server container for HTTP MCP servers. Probes that are not set use MCP-aware
+	// defaults.
+	// +optional
+	Probes *Probes `json:"probes,omitempty"`
+
+	// PackageCache keeps the npm and uv package caches of MCP servers run with npx
+	// or uvx on a volume, and optionally installs the package before the server
+	// starts so that pods do not download it on every start.
+	// +optional
+	PackageCache *PackageCache `json:"packageCache,omitempty"`
+
+	// PodTemplate is a partial pod template that is strategic-merged over the pod
+	// template generated for the MCP server, for pod settings that have no dedicated
+	// field such as priorityClassName, topologySpreadConstraints, runtimeClassName,
+	// hostAliases, dnsConfig or container lifecycle hooks. The overlay takes
+	// precedence over the generated pod template:
+	// - fields set in the overlay replace the generated values and maps are merged;
+	// - containers, initContainers and volumes are merged by name: an entry named
+	//   like a generated one (mcp-server, transport-adapter, copy-binary, config)
+	//   patches it and any other entry is appended;
+	// - generated list entries can be removed with the $patch: delete directive.
+	// The selector labels and the config hash annotation cannot be overridden. The
+	// overlay cannot weaken the security profile either: its security contexts, host
+	// namespaces and, for the sandboxed profile, runtimeClassName are checked against
+	// the profile, whose settings are applied after the overlay. Its containers are
+	// checked against the MCPServerPolicies like the other containers.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	PodTemplate *runtime.RawExtension `json:"podTemplate,omitempty"`
+
 	// PodSecurityContext defines the security context for the entire pod.
 	// Use this to configure pod-level security settings such as:
 	// - runAsUser/runAsGroup: Default user/group for all containers
@@ -398,7 +973,10 @@ type ServiceAccountConfig struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=mcps;mcp
+// +kubebuilder:printcolumn:name="Class",type="string",JSONPath=".status.className"
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.url"
+// +kubebuilder:printcolumn:name="Revision",type="string",JSONPath=".status.currentRevision",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:categories=kagent
 
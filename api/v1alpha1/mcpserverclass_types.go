@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MCPServerClassDefaultAnnotation marks an MCPServerClass as the default class, applied
+// to MCPServers that do not reference a class. Its value must be "true".
+const MCPServerClassDefaultAnnotation = "mcpserverclass.kagent.dev/is-default-class"
+
+// MCPServerClassSpec defines the defaults and policy applied to the MCPServers of a class.
+type MCPServerClassSpec struct {
+	// Deployment holds the deployment settings applied to the MCPServers of the class.
+	// Settings made on an MCPServer take precedence: unset fields are filled from the
+	// class, labels, annotations and node selectors are merged key by key, and
+	// environment variables are merged by name.
+	// +optional
+	Deployment *MCPServerClassDeployment `json:"deployment,omitempty"`
+
+	// AllowedTransports restricts the transport types of the MCPServers of the class.
+	// All transports are allowed when empty.
+	// +optional
+	// +listType=set
+	AllowedTransports []TransportType `json:"allowedTransports,omitempty"`
+
+	// AdapterVersion is the version of the agentgateway transport adapter image run by
+	// the MCPServers of the class that do not set deployment.initContainer.image.
+	// Defaults to the version configured on the controller.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9.\-]+$`
+	AdapterVersion string `json:"adapterVersion,omitempty"`
+
+	// SecurityProfile is the security profile of the MCPServers of the class that do
+	// not select one. It takes precedence over the default profile of the controller.
+	// +optional
+	// +kubebuilder:validation:Enum=baseline;restricted;sandboxed
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+}
+
+// MCPServerClassDeployment holds the deployment settings that an MCPServerClass applies
+// to its MCPServers. The fields have the meaning of their MCPServerDeployment counterparts.
+type MCPServerClassDeployment struct {
+	// ImagePullPolicy defines the pull policy for the container image.
+	// +optional
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets is a list of references to secrets used to pull the images.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// EnvVars defines environment variables of the MCP server container.
+	// +optional
+	EnvVars []corev1.EnvVar `json:"envVars,omitempty"`
+
+	// Labels defines additional labels for the pods.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations defines additional annotations for the pods.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Resources defines the compute resources of the MCP server container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// SecurityContext defines the security context of the MCP server container.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// PodSecurityContext defines the security context of the pods.
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// InitContainer defines the configuration of the transport adapter init container.
+	// +optional
+	InitContainer *InitContainerConfig `json:"initContainer,omitempty"`
+
+	// Probes configures the probes of the container serving MCP traffic.
+	// +optional
+	Probes *Probes `json:"probes,omitempty"`
+
+	// Tolerations defines the tolerations of the pods.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity defines the scheduling constraints of the pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// NodeSelector selects the nodes the pods can be scheduled on.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=mcpclass
+// +kubebuilder:printcolumn:name="Default",type="string",JSONPath=".metadata.annotations.mcpserverclass\\.kagent\\.dev/is-default-class"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:categories=kagent
+
+// MCPServerClass is a cluster-wide set of defaults and policy for MCPServers, like an
+// IngressClass or a StorageClass. MCPServers select a class with spec.className; the
+// class annotated with mcpserverclass.kagent.dev/is-default-class applies to the
+// MCPServers that do not.
+type MCPServerClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MCPServerClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MCPServerClassList contains a list of MCPServerClass.
+type MCPServerClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MCPServerClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MCPServerClass{}, &MCPServerClassList{})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PolicyMode defines what happens to the MCPServers violating an MCPServerPolicy.
+type PolicyMode string
+
+const (
+	// PolicyModeAudit reports the violations on the Accepted condition and as admission
+	// warnings, without rejecting the MCPServer.
+	PolicyModeAudit PolicyMode = "Audit"
+
+	// PolicyModeEnforce rejects the MCPServers violating the policy at admission, and
+	// keeps the controller from deploying them.
+	PolicyModeEnforce PolicyMode = "Enforce"
+)
+
+// MCPServerPolicySpec defines the guardrails MCPServers must respect.
+type MCPServerPolicySpec struct {
+	// NamespaceSelector selects the namespaces of the MCPServers the policy applies to.
+	// The policy applies to all namespaces when it is not set.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Mode is Enforce to reject the MCPServers violating the policy, or Audit to only
+	// report the violations.
+	// +optional
+	// +kubebuilder:default=Enforce
+	// +kubebuilder:validation:Enum=Audit;Enforce
+	Mode PolicyMode `json:"mode,omitempty"`
+
+	// AllowedImages lists the images the containers of MCPServers may run. An entry
+	// ending with / allows the images under that registry or repository path, such as
+	// ghcr.io/our-org/. Other entries allow exactly that image, with any tag or digest.
+	// Any image is allowed when empty. The default images of npx and uvx MCP servers are
+	// checked too; the transport adapter images chosen by the controller are not.
+	// +optional
+	AllowedImages []string `json:"allowedImages,omitempty"`
+
+	// AllowedPackages lists the packages npx and uvx MCP servers may run. Entries are
+	// matched against the package name without its version, and may contain shell
+	// patterns, such as @modelcontextprotocol/*. Any package is allowed when empty.
+	// +optional
+	AllowedPackages []string `json:"allowedPackages,omitempty"`
+
+	// DenyPrivileged forbids privileged containers, including sidecars and containers of
+	// the pod template overlay, and pods sharing the host network, PID or IPC namespaces.
+	// +optional
+	DenyPrivileged bool `json:"denyPrivileged,omitempty"`
+
+	// AllowedCapabilities lists the capabilities containers may add. Any capability is
+	// allowed when empty.
+	// +optional
+	AllowedCapabilities []corev1.Capability `json:"allowedCapabilities,omitempty"`
+
+	// RequireResourceLimits requires the MCP server container and the sidecars to set CPU
+	// and memory limits.
+	// +optional
+	RequireResourceLimits bool `json:"requireResourceLimits,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=mcppolicy
+// +kubebuilder:printcolumn:name="Mode",type="string",JSONPath=".spec.mode"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:categories=kagent
+
+// MCPServerPolicy is a cluster-wide policy restricting the images, packages and
+// privileges of the MCPServers in the namespaces it selects. It is evaluated when
+// MCPServers are admitted and again when they are reconciled.
+type MCPServerPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MCPServerPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MCPServerPolicyList contains a list of MCPServerPolicy.
+type MCPServerPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MCPServerPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MCPServerPolicy{}, &MCPServerPolicyList{})
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TemplateParameterType defines the type of a template parameter.
+type TemplateParameterType string
+
+const (
+	// TemplateParameterTypeString is a parameter substituted as text.
+	TemplateParameterTypeString TemplateParameterType = "string"
+
+	// TemplateParameterTypeInteger is a parameter holding an integer, such as a port.
+	TemplateParameterTypeInteger TemplateParameterType = "integer"
+
+	// TemplateParameterTypeBoolean is a parameter holding true or false.
+	TemplateParameterTypeBoolean TemplateParameterType = "boolean"
+)
+
+// TemplateParameter declares a parameter of an MCPServerTemplate.
+type TemplateParameter struct {
+	// Name is the name of the parameter, referenced as $(params.<name>) in the template.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_-]*$`
+	Name string `json:"name"`
+
+	// Type is the type the value of the parameter must have. A reference making up a
+	// whole string value of the template is replaced by the typed value, so that
+	// integer and boolean parameters can set integer and boolean fields. String fields
+	// receive the text of the value.
+	// +optional
+	// +kubebuilder:default=string
+	// +kubebuilder:validation:Enum=string;integer;boolean
+	Type TemplateParameterType `json:"type,omitempty"`
+
+	// Description describes the parameter to the users of the template.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Default is the value of the parameter when the MCPServer does not set it. The
+	// parameter is required when there is no default.
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+// MCPServerTemplateSpec defines a parameterized MCPServer spec.
+type MCPServerTemplateSpec struct {
+	// Description describes the MCP server provided by the template.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Parameters declares the parameters of the template.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Parameters []TemplateParameter `json:"parameters,omitempty"`
+
+	// Template is the MCPServer spec of the instances. $(params.<name>) references in
+	// its string values are replaced with the parameters of the instance. The expanded
+	// spec of every instance is validated against the MCPServer schema, whose defaults
+	// apply, before it is deployed.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	Template runtime.RawExtension `json:"template"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=mcptemplate
+// +kubebuilder:printcolumn:name="Description",type="string",JSONPath=".spec.description"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:categories=kagent
+
+// MCPServerTemplate is a cluster-wide catalog entry holding a parameterized MCPServer
+// spec. MCPServers instantiate it with spec.templateRef and spec.parameters.
+type MCPServerTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MCPServerTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MCPServerTemplateList contains a list of MCPServerTemplate.
+type MCPServerTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MCPServerTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MCPServerTemplate{}, &MCPServerTemplateList{})
+}
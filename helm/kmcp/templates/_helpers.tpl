@@ -102,5 +102,58 @@ Create controller manager container args
 {{- $namespaces := .Values.rbac.namespaces | uniq }}
 {{- $args = append $args (printf "--watch-namespaces=%s" (join "," $namespaces)) }}
 {{- end }}
+{{- if .Values.controller.watchNamespaceSelector }}
+{{- $args = append $args (printf "--watch-namespace-selector=%s" .Values.controller.watchNamespaceSelector) }}
+{{- end }}
+{{- if and .Values.controller.sharding (gt (int .Values.controller.sharding.shards) 1) }}
+{{- $args = append $args (printf "--shards=%d" (int .Values.controller.sharding.shards)) }}
+{{- /* The shard is the ordinal of the StatefulSet replica, expanded by the kubelet */}}
+{{- $args = append $args "--shard=$(KMCP_SHARD)" }}
+{{- end }}
+{{- if and .Values.controller.transportAdapter .Values.controller.transportAdapter.backend }}
+{{- $args = append $args (printf "--transport-adapter-backend=%s" .Values.controller.transportAdapter.backend) }}
+{{- end }}
+{{- if and .Values.controller.securityProfile .Values.controller.securityProfile.default }}
+{{- $args = append $args (printf "--default-security-profile=%s" .Values.controller.securityProfile.default) }}
+{{- end }}
+{{- if and .Values.controller.securityProfile .Values.controller.securityProfile.sandboxRuntimeClassName }}
+{{- $args = append $args (printf "--sandbox-runtime-class-name=%s" .Values.controller.securityProfile.sandboxRuntimeClassName) }}
+{{- end }}
+{{- if .Values.controller.metrics.monitorLabels }}
+{{- $labels := list }}
+{{- range $key, $value := .Values.controller.metrics.monitorLabels }}
+{{- $labels = append $labels (printf "%s=%s" $key $value) }}
+{{- end }}
+{{- $args = append $args (printf "--monitor-labels=%s" (join "," $labels)) }}
+{{- end }}
+{{- if .Values.controller.runnerImages }}
+{{- $images := list }}
+{{- range $runner, $image := .Values.controller.runnerImages }}
+{{- $images = append $images (printf "%s=%s" $runner $image) }}
+{{- end }}
+{{- $args = append $args (printf "--runner-images=%s" (join "," $images)) }}
+{{- end }}
+{{- with .Values.controller.reconcile }}
+{{- if .maxConcurrentReconciles }}
+{{- $args = append $args (printf "--max-concurrent-reconciles=%v" .maxConcurrentReconciles) }}
+{{- end }}
+{{- if .backoffBase }}
+{{- $args = append $args (printf "--reconcile-backoff-base=%s" .backoffBase) }}
+{{- end }}
+{{- if .backoffMax }}
+{{- $args = append $args (printf "--reconcile-backoff-max=%s" .backoffMax) }}
+{{- end }}
+{{- end }}
+{{- if and .Values.controller.webhook .Values.controller.webhook.enabled }}
+{{- $args = append $args "--enable-webhooks" }}
+{{- $args = append $args "--webhook-cert-path=/tmp/k8s-webhook-server/serving-certs" }}
+{{- end }}
+{{- if and .Values.controller.tracing .Values.controller.tracing.otlpEndpoint }}
+{{- $args = append $args (printf "--tracing-otlp-endpoint=%s" .Values.controller.tracing.otlpEndpoint) }}
+{{- $args = append $args (printf "--tracing-sampling-ratio=%v" .Values.controller.tracing.samplingRatio) }}
+{{- end }}
+{{- if .Values.controller.config }}
+{{- $args = append $args "--config=/etc/kmcp/config.yaml" }}
+{{- end }}
 {{- toYaml $args }}
 {{- end }} 
\ No newline at end of file
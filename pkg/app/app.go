@@ -17,16 +17,23 @@ limitations under the License.
 package app
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -42,6 +49,7 @@ import (
 
 	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
 	"github.com/kagent-dev/kmcp/pkg/controller"
+	"github.com/kagent-dev/kmcp/pkg/controller/config"
 	"github.com/kagent-dev/kmcp/pkg/controller/transportadapter"
 	// +kubebuilder:scaffold:imports
 )
@@ -68,6 +76,7 @@ type Config struct {
 		CertKey  string
 	}
 	Webhook struct {
+		Enabled  bool
 		CertPath string
 		CertName string
 		CertKey  string
@@ -77,6 +86,28 @@ type Config struct {
 	SecureMetrics   bool
 	EnableHTTP2     bool
 	WatchNamespaces string
+	AdapterBackend  string
+	MonitorLabels   string
+	RunnerImages    string
+	SecurityProfile struct {
+		Default                 string
+		SandboxRuntimeClassName string
+	}
+	Tracing struct {
+		OTLPEndpoint  string
+		SamplingRatio float64
+	}
+	ConfigFile string
+	Scope      struct {
+		NamespaceSelector string
+		Shards            int
+		Shard             int
+	}
+	Reconcile struct {
+		MaxConcurrent int
+		BackoffBase   time.Duration
+		BackoffMax    time.Duration
+	}
 }
 
 func (cfg *Config) SetFlags(commandLine *flag.FlagSet) {
@@ -97,6 +128,9 @@ func (cfg *Config) SetFlags(commandLine *flag.FlagSet) {
 		"The name of the metrics server certificate file.",
 	)
 	commandLine.StringVar(&cfg.Metrics.CertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
+	commandLine.BoolVar(&cfg.Webhook.Enabled, "enable-webhooks", false,
+		"If set, the validating webhook checking MCPServers against MCPServerPolicies is served. "+
+			"It requires a webhook certificate.")
 	commandLine.StringVar(
 		&cfg.Webhook.CertPath,
 		"webhook-cert-path",
@@ -114,6 +148,46 @@ func (cfg *Config) SetFlags(commandLine *flag.FlagSet) {
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	commandLine.StringVar(&cfg.WatchNamespaces, "watch-namespaces", "",
 		"Comma-separated list of namespaces the controller watches. If empty, watches all namespaces.")
+	commandLine.StringVar(&cfg.Scope.NamespaceSelector, "watch-namespace-selector", "",
+		"Label selector of the namespaces whose MCPServers and MCPGateways the controller reconciles, "+
+			"e.g. kmcp.kagent.dev/tenant. Namespaces are selected as their labels change. "+
+			"If empty, all watched namespaces are reconciled.")
+	commandLine.IntVar(&cfg.Scope.Shards, "shards", 1,
+		"The number of controller shards splitting the MCPServers and MCPGateways, by their "+
+			kagentdevv1alpha1.ShardLabel+" label or else the hash of their namespace and name.")
+	commandLine.IntVar(&cfg.Scope.Shard, "shard", 0,
+		"The shard of this controller, from 0 to the number of shards minus 1. "+
+			"Each shard runs its own leader election.")
+	commandLine.StringVar(&cfg.AdapterBackend, "transport-adapter-backend", transportadapter.AgentgatewayBackendName,
+		"The transport adapter backend used for stdio MCP servers that do not select one. "+
+			"One of: "+strings.Join(transportadapter.AdapterBackendNames(), ", ")+".")
+	commandLine.StringVar(&cfg.MonitorLabels, "monitor-labels", "",
+		"Comma-separated list of key=value labels added to the ServiceMonitors and PodMonitors "+
+			"created for MCPServers exposing metrics, e.g. release=prometheus.")
+	commandLine.StringVar(&cfg.RunnerImages, "runner-images", "",
+		"Comma-separated list of runner=image pairs setting the default images of the commands running "+
+			"MCP server packages, e.g. npx=registry.example.com/node:24. Unknown runners are registered "+
+			"with the image only; their other settings are configured in the controller configuration file.")
+	commandLine.StringVar(&cfg.SecurityProfile.Default, "default-security-profile", "",
+		"The security profile applied to MCPServers that do not select one. "+
+			"One of: baseline, restricted, sandboxed. If empty, no profile is applied.")
+	commandLine.StringVar(&cfg.SecurityProfile.SandboxRuntimeClassName, "sandbox-runtime-class-name",
+		transportadapter.DefaultSandboxRuntimeClassName,
+		"The runtime class of the pods of MCPServers using the sandboxed security profile.")
+	commandLine.StringVar(&cfg.Tracing.OTLPEndpoint, "tracing-otlp-endpoint", "",
+		"The OTLP gRPC endpoint the controller exports the traces of its reconcile loops to. "+
+			"If empty, tracing is disabled.")
+	commandLine.Float64Var(&cfg.Tracing.SamplingRatio, "tracing-sampling-ratio", 1,
+		"The ratio of reconcile traces that are sampled, between 0 and 1.")
+	commandLine.StringVar(&cfg.ConfigFile, "config", "",
+		"The controller configuration file. Its settings override the flags, and changes to it "+
+			"are applied without a restart. If empty, the flags and defaults are used.")
+	commandLine.IntVar(&cfg.Reconcile.MaxConcurrent, "max-concurrent-reconciles", 1,
+		"The number of MCPServers, and of MCPGateways, reconciled in parallel.")
+	commandLine.DurationVar(&cfg.Reconcile.BackoffBase, "reconcile-backoff-base", controller.DefaultBackoffBase,
+		"The delay before a failed reconcile is retried, doubled on each consecutive failure.")
+	commandLine.DurationVar(&cfg.Reconcile.BackoffMax, "reconcile-backoff-max", controller.DefaultBackoffMax,
+		"The maximum delay between the retries of a failed reconcile.")
 }
 
 // PluginFactory creates a TranslatorPlugin when provided with the client and scheme.
@@ -143,6 +217,75 @@ func filterValidNamespaces(namespaces []string) []string {
 	return result
 }
 
+// parseLabels parses a comma-separated list of key=value labels.
+func parseLabels(value string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid label %q, must be key=value", pair)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return labels, nil
+}
+
+// baseControllerConfig returns the controller configuration that the configuration file
+// overrides: the defaults, with the settings of the flags and of the deprecated
+// environment variables.
+func baseControllerConfig(cfg *Config) (*config.ControllerConfig, error) {
+	base := config.Default()
+	base.SecurityProfile.Default = kagentdevv1alpha1.SecurityProfile(cfg.SecurityProfile.Default)
+	base.SecurityProfile.SandboxRuntimeClassName = cfg.SecurityProfile.SandboxRuntimeClassName
+	runnerImages, err := parseLabels(cfg.RunnerImages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --runner-images: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(runnerImages)) {
+		base.Runners = append(base.Runners, transportadapter.Runner{Name: name, Image: runnerImages[name]})
+	}
+	if version := os.Getenv("TRANSPORT_ADAPTER_VERSION"); version != "" {
+		setupLog.Info("TRANSPORT_ADAPTER_VERSION is deprecated, set transportAdapter.version in the controller configuration")
+		base.TransportAdapter.Version = version
+	}
+	if os.Getenv("DISABLE_KGATEWAY_MCP_APP_PROTOCOL") == "true" {
+		setupLog.Info("DISABLE_KGATEWAY_MCP_APP_PROTOCOL is deprecated, disable the " +
+			config.KgatewayMCPAppProtocol + " feature gate in the controller configuration")
+		base.FeatureGates[config.KgatewayMCPAppProtocol] = false
+	}
+	return base, nil
+}
+
+// controllerScope returns the scope of the resources reconciled by the controller.
+func controllerScope(cfg *Config) (controller.Scope, error) {
+	scope := controller.Scope{Shards: cfg.Scope.Shards, Shard: cfg.Scope.Shard}
+	if cfg.Scope.NamespaceSelector != "" {
+		selector, err := labels.Parse(cfg.Scope.NamespaceSelector)
+		if err != nil {
+			return scope, fmt.Errorf("invalid --watch-namespace-selector: %w", err)
+		}
+		scope.NamespaceSelector = selector
+	}
+	if err := scope.Validate(); err != nil {
+		return scope, fmt.Errorf("invalid sharding: %w", err)
+	}
+	return scope, nil
+}
+
+// leaderElectionID returns the leader election ID of the controller. Each shard elects
+// its own leader.
+func leaderElectionID(scope controller.Scope) string {
+	const id = "90217b08.kagent.dev"
+	if scope.Shards > 1 {
+		return fmt.Sprintf("shard-%d.%s", scope.Shard, id)
+	}
+	return id
+}
+
 // configureNamespaceWatching returns a DefaultNamespaces map for cache.Options
 // when a non-empty namespace list is provided, restricting the controller's
 // watches to those namespaces. Returns nil (cluster-wide) when the list is empty.
@@ -171,6 +314,49 @@ func Start(getExtensionConfig GetExtensionConfig) {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if _, err := transportadapter.LookupAdapterBackend(cfg.AdapterBackend); err != nil {
+		setupLog.Error(err, "invalid --transport-adapter-backend")
+		os.Exit(1)
+	}
+
+	defaultSecurityProfile := kagentdevv1alpha1.SecurityProfile(cfg.SecurityProfile.Default)
+	if err := transportadapter.CheckSecurityProfile(&kagentdevv1alpha1.MCPServer{}, defaultSecurityProfile); err != nil {
+		setupLog.Error(err, "invalid --default-security-profile")
+		os.Exit(1)
+	}
+
+	baseConfig, err := baseControllerConfig(&cfg)
+	if err != nil {
+		setupLog.Error(err, "invalid controller flags")
+		os.Exit(1)
+	}
+	scope, err := controllerScope(&cfg)
+	if err != nil {
+		setupLog.Error(err, "invalid controller flags")
+		os.Exit(1)
+	}
+	controllerConfig, err := config.NewWatcher(cfg.ConfigFile, baseConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to load the controller configuration")
+		os.Exit(1)
+	}
+
+	monitorLabels, err := parseLabels(cfg.MonitorLabels)
+	if err != nil {
+		setupLog.Error(err, "invalid --monitor-labels")
+		os.Exit(1)
+	}
+
+	shutdownTracing := func(context.Context) error { return nil }
+	if cfg.Tracing.OTLPEndpoint != "" {
+		shutdownTracing, err = setupTracing(context.Background(), cfg.Tracing.OTLPEndpoint, cfg.Tracing.SamplingRatio)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		setupLog.Info("exporting controller traces", "endpoint", cfg.Tracing.OTLPEndpoint)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -284,6 +470,12 @@ func Start(getExtensionConfig GetExtensionConfig) {
 	} else {
 		setupLog.Info("watching all namespaces")
 	}
+	if scope.NamespaceSelector != nil {
+		setupLog.Info("reconciling selected namespaces", "selector", scope.NamespaceSelector.String())
+	}
+	if scope.Shards > 1 {
+		setupLog.Info("sharding the controller", "shard", scope.Shard, "shards", scope.Shards)
+	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
@@ -291,9 +483,17 @@ func Start(getExtensionConfig GetExtensionConfig) {
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: cfg.ProbeAddr,
 		LeaderElection:         cfg.LeaderElection,
-		LeaderElectionID:       "90217b08.kagent.dev",
+		LeaderElectionID:       leaderElectionID(scope),
 		Cache: cache.Options{
 			DefaultNamespaces: configureNamespaceWatching(watchNamespacesList),
+			// Only the binding Secrets of MCPServers are cached, not every Secret of the cluster
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Secret{}: {
+					Label: labels.SelectorFromSet(labels.Set{kagentdevv1alpha1.MCPServerBindingLabel: "true"}),
+				},
+				// Pods are watched to follow the readiness of MCPServers
+				&corev1.Pod{}: controller.PodCacheOptions(),
+			},
 		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
@@ -318,16 +518,53 @@ func Start(getExtensionConfig GetExtensionConfig) {
 		plugins = append(plugins, plugin)
 	}
 
+	reconcileOptions := controller.ReconcileOptions{
+		MaxConcurrentReconciles: cfg.Reconcile.MaxConcurrent,
+		BackoffBase:             cfg.Reconcile.BackoffBase,
+		BackoffMax:              cfg.Reconcile.BackoffMax,
+	}
 	if err = (&controller.MCPServerReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Plugins:        plugins,
+		AdapterBackend: cfg.AdapterBackend,
+		MonitorLabels:  monitorLabels,
+		Config:         controllerConfig,
+		Recorder:       mgr.GetEventRecorderFor("mcpserver-controller"),
+		APIReader:      mgr.GetAPIReader(),
+		Options:        reconcileOptions,
+		Scope:          scope,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MCPServer")
+		os.Exit(1)
+	}
+	if err = (&controller.MCPGatewayReconciler{
 		Client:  mgr.GetClient(),
 		Scheme:  mgr.GetScheme(),
-		Plugins: plugins,
+		Config:  controllerConfig,
+		Options: reconcileOptions,
+		Scope:   scope,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "MCPServer")
+		setupLog.Error(err, "unable to create controller", "controller", "MCPGateway")
 		os.Exit(1)
 	}
+	if cfg.Webhook.Enabled {
+		if err = (&controller.MCPServerValidator{
+			Client:         mgr.GetClient(),
+			AdapterBackend: cfg.AdapterBackend,
+			Config:         controllerConfig,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MCPServer")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if err := mgr.Add(controllerConfig); err != nil {
+		setupLog.Error(err, "unable to add controller configuration watcher to manager")
+		os.Exit(1)
+	}
+
 	if metricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
 		if err := mgr.Add(metricsCertWatcher); err != nil {
@@ -354,7 +591,11 @@ func Start(getExtensionConfig GetExtensionConfig) {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctrl.SetupSignalHandler())
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		setupLog.Error(shutdownErr, "unable to flush traces")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
@@ -104,3 +104,117 @@ func TestConfigureNamespaceWatching(t *testing.T) {
 		}
 	})
 }
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "empty string",
+			input:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:     "multiple labels",
+			input:    "release=prometheus, team = platform",
+			expected: map[string]string{"release": "prometheus", "team": "platform"},
+		},
+		{
+			name:    "missing value separator",
+			input:   "release",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseLabels(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("got %v, want %v", result, tt.expected)
+			}
+			for key, value := range tt.expected {
+				if result[key] != value {
+					t.Errorf("label %q: got %q, want %q", key, result[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestControllerScope(t *testing.T) {
+	tests := []struct {
+		name             string
+		selector         string
+		shards           int
+		shard            int
+		wantErr          bool
+		wantElectionID   string
+		wantSelectorText string
+	}{
+		{
+			name:           "defaults",
+			shards:         1,
+			wantElectionID: "90217b08.kagent.dev",
+		},
+		{
+			name:             "namespace selector",
+			selector:         "kmcp.kagent.dev/tenant, env in (prod)",
+			shards:           1,
+			wantElectionID:   "90217b08.kagent.dev",
+			wantSelectorText: "env in (prod),kmcp.kagent.dev/tenant",
+		},
+		{
+			name:           "second of three shards",
+			shards:         3,
+			shard:          1,
+			wantElectionID: "shard-1.90217b08.kagent.dev",
+		},
+		{
+			name:     "invalid selector",
+			selector: "env in prod",
+			shards:   1,
+			wantErr:  true,
+		},
+		{
+			name:    "shard out of range",
+			shards:  2,
+			shard:   2,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Scope.NamespaceSelector = tt.selector
+			cfg.Scope.Shards = tt.shards
+			cfg.Scope.Shard = tt.shard
+			scope, err := controllerScope(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if id := leaderElectionID(scope); id != tt.wantElectionID {
+				t.Errorf("leader election ID: got %q, want %q", id, tt.wantElectionID)
+			}
+			if tt.wantSelectorText == "" {
+				if scope.NamespaceSelector != nil {
+					t.Errorf("expected no namespace selector, got %q", scope.NamespaceSelector)
+				}
+			} else if scope.NamespaceSelector.String() != tt.wantSelectorText {
+				t.Errorf("namespace selector: got %q, want %q", scope.NamespaceSelector, tt.wantSelectorText)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const tracingServiceName = "kmcp-controller-manager"
+
+// setupTracing installs a global OpenTelemetry tracer provider that exports the spans
+// of the controller to the OTLP gRPC collector at endpoint. The returned function
+// flushes the pending spans and must be called before the process exits.
+func setupTracing(
+	ctx context.Context,
+	endpoint string,
+	samplingRatio float64,
+) (func(context.Context) error, error) {
+	if samplingRatio < 0 || samplingRatio > 1 {
+		return nil, fmt.Errorf("tracing sampling ratio must be between 0 and 1, got %v", samplingRatio)
+	}
+
+	exporterOpts := []otlptracegrpc.Option{}
+	if parsed, err := url.Parse(endpoint); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithEndpoint(parsed.Host))
+		if parsed.Scheme == "http" {
+			exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
+		}
+	} else {
+		// host:port without a scheme, as accepted by OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", tracingServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
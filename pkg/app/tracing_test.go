@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+)
+
+// fakeCollector is a stand-in for an OpenTelemetry collector that records the
+// names of the spans it receives.
+type fakeCollector struct {
+	collectortrace.UnimplementedTraceServiceServer
+
+	mu    sync.Mutex
+	spans []string
+}
+
+func (c *fakeCollector) Export(
+	_ context.Context,
+	req *collectortrace.ExportTraceServiceRequest,
+) (*collectortrace.ExportTraceServiceResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, resourceSpans := range req.GetResourceSpans() {
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			for _, span := range scopeSpans.GetSpans() {
+				c.spans = append(c.spans, span.GetName())
+			}
+		}
+	}
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+func startFakeCollector(t *testing.T) (*fakeCollector, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	collector := &fakeCollector{}
+	server := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(server, collector)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return collector, listener.Addr().String()
+}
+
+func TestSetupTracingExportsSpans(t *testing.T) {
+	collector, addr := startFakeCollector(t)
+	ctx := context.Background()
+
+	// setupTracing installs the global provider and propagator, restore them for the other tests
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	shutdown, err := setupTracing(ctx, "http://"+addr, 1)
+	if err != nil {
+		t.Fatalf("failed to set up tracing: %v", err)
+	}
+	_, span := otel.Tracer("test").Start(ctx, "MCPServer.Reconcile")
+	span.End()
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("failed to flush spans: %v", err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if len(collector.spans) != 1 || collector.spans[0] != "MCPServer.Reconcile" {
+		t.Errorf("expected the collector to receive the reconcile span, got %v", collector.spans)
+	}
+}
+
+func TestSetupTracingRejectsInvalidSamplingRatio(t *testing.T) {
+	if _, err := setupTracing(context.Background(), "localhost:4317", 1.5); err == nil {
+		t.Errorf("expected an error for a sampling ratio above 1")
+	}
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// rollbackCmd requests the rollback of an MCPServer from the controller
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <name>",
+	Short: "Roll back an MCPServer to an earlier revision",
+	Long: `Roll back an MCPServer to an earlier revision.
+
+The controller stores every spec of an MCPServer as a revision. Without --to-revision,
+the MCPServer is rolled back to the last revision that became ready. The revision it is
+rolled back from is not rolled out again until the spec of the MCPServer changes.`,
+	Example: `  kmcp rollback my-server                    # Roll back to the last revision that became ready
+  kmcp rollback my-server --to-revision 3    # Roll back to revision 3
+  kmcp rollback my-server -n staging         # Roll back an MCPServer of the staging namespace`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRollback,
+}
+
+var (
+	rollbackNamespace  string
+	rollbackToRevision int64
+)
+
+func init() {
+	addRootSubCmd(rollbackCmd)
+
+	currentNamespace, err := getCurrentNamespaceFromKubeconfig()
+	if err != nil {
+		currentNamespace = "default"
+	}
+
+	rollbackCmd.Flags().StringVarP(&rollbackNamespace, "namespace", "n", currentNamespace, "Kubernetes namespace")
+	rollbackCmd.Flags().Int64Var(&rollbackToRevision, "to-revision", 0,
+		"Revision to roll back to (default: the last revision that became ready)")
+}
+
+func runRollback(_ *cobra.Command, args []string) error {
+	name := args[0]
+	ctx := context.Background()
+
+	kubeClient, err := newKubeClient()
+	if err != nil {
+		return err
+	}
+
+	server := &v1alpha1.MCPServer{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Name: name, Namespace: rollbackNamespace}, server); err != nil {
+		return fmt.Errorf("failed to get MCPServer '%s': %w", name, err)
+	}
+
+	if rollbackToRevision > 0 {
+		if err := checkRevisionExists(ctx, kubeClient, server, rollbackToRevision); err != nil {
+			return err
+		}
+	} else if server.Status.LastGoodRevision == "" {
+		return fmt.Errorf("no revision of MCPServer '%s' became ready, use --to-revision to select one", name)
+	}
+
+	patch := client.MergeFrom(server.DeepCopy())
+	if server.Annotations == nil {
+		server.Annotations = map[string]string{}
+	}
+	server.Annotations[v1alpha1.MCPServerRollbackAnnotation] = strconv.FormatInt(rollbackToRevision, 10)
+	if err := kubeClient.Patch(ctx, server, patch); err != nil {
+		return fmt.Errorf("failed to request the rollback of MCPServer '%s': %w", name, err)
+	}
+
+	if rollbackToRevision > 0 {
+		fmt.Printf("✅ Rollback of MCPServer '%s' to revision %d requested\n", name, rollbackToRevision)
+	} else {
+		fmt.Printf("✅ Rollback of MCPServer '%s' to revision %s requested\n", name, server.Status.LastGoodRevision)
+	}
+	fmt.Printf("💡 Check status with: kubectl get mcpserver %s -n %s -o wide\n", name, rollbackNamespace)
+	return nil
+}
+
+// checkRevisionExists checks that the MCPServer has a revision with the given number.
+func checkRevisionExists(
+	ctx context.Context,
+	kubeClient client.Client,
+	server *v1alpha1.MCPServer,
+	number int64,
+) error {
+	revisions := &appsv1.ControllerRevisionList{}
+	if err := kubeClient.List(ctx, revisions,
+		client.InNamespace(server.Namespace),
+		client.MatchingLabels{v1alpha1.MCPServerNameLabel: server.Name},
+	); err != nil {
+		return fmt.Errorf("failed to list the revisions of MCPServer '%s': %w", server.Name, err)
+	}
+
+	available := make([]int64, 0, len(revisions.Items))
+	for _, revision := range revisions.Items {
+		if revision.Revision == number {
+			return nil
+		}
+		available = append(available, revision.Revision)
+	}
+	return fmt.Errorf("revision %d of MCPServer '%s' not found, available revisions: %v", number, server.Name, available)
+}
+
+func newKubeClient() (client.Client, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	kubeClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return kubeClient, nil
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config loads the configuration file of the controller.
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/kagent-dev/kmcp/pkg/controller/transportadapter"
+)
+
+const (
+	// APIVersion is the version of the configuration file format.
+	APIVersion = "kmcp.kagent.dev/v1alpha1"
+	// Kind is the kind of the configuration file.
+	Kind = "ControllerConfig"
+
+	// KgatewayMCPAppProtocol sets the appProtocol that lets kgateway route to the
+	// Services of MCPServers and MCPGateways as MCP backends. Enabled by default.
+	KgatewayMCPAppProtocol = "KgatewayMCPAppProtocol"
+
+	defaultNotReadyInterval     = 5 * time.Minute
+	defaultToolsCheckInterval   = 10 * time.Second
+	defaultToolsRecheckInterval = 10 * time.Minute
+)
+
+// featureGates are the known feature gates and their default.
+var featureGates = map[string]bool{
+	KgatewayMCPAppProtocol: true,
+}
+
+// ControllerConfig is the configuration file of the controller.
+type ControllerConfig struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// TransportAdapter is the agentgateway transport adapter image of stdio MCP servers
+	// and MCPGateways that do not set one.
+	TransportAdapter TransportAdapter `json:"transportAdapter,omitempty"`
+
+	// Runners register the commands running MCP server packages, or override the
+	// fields of the built-in runners of the same name, such as the image of npx.
+	Runners []transportadapter.Runner `json:"runners,omitempty"`
+
+	// SecurityProfile is the security profile of MCPServers that do not select one.
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+
+	// Requeue are the intervals at which resources are reconciled again while waiting.
+	Requeue Requeue `json:"requeue,omitempty"`
+
+	// FeatureGates enables or disables features by name.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// TransportAdapter configures the agentgateway transport adapter image.
+type TransportAdapter struct {
+	Repository string `json:"repository,omitempty"`
+	Version    string `json:"version,omitempty"`
+}
+
+// SecurityProfile configures the default security profile of MCPServers.
+type SecurityProfile struct {
+	Default                 kagentdevv1alpha1.SecurityProfile `json:"default,omitempty"`
+	SandboxRuntimeClassName string                            `json:"sandboxRuntimeClassName,omitempty"`
+}
+
+// Requeue configures the requeue intervals of the controllers.
+type Requeue struct {
+	// NotReady is the interval at which MCPServers and MCPGateways whose workload is not
+	// ready are checked again. Readiness changes of the workloads and their pods trigger
+	// a reconcile as they happen, so this is only a safety net.
+	NotReady metav1.Duration `json:"notReady,omitempty"`
+	// ToolsCheck is the interval at which MCPServers whose tools are still to be compared
+	// to their pinned tools are checked again.
+	ToolsCheck metav1.Duration `json:"toolsCheck,omitempty"`
+	// ToolsRecheck is the interval at which the tools of MCPServers pinning their tools
+	// are listed again, in addition to when their pods restart.
+	ToolsRecheck metav1.Duration `json:"toolsRecheck,omitempty"`
+}
+
+// Default returns the configuration used when no configuration file is given.
+func Default() *ControllerConfig {
+	defaults := transportadapter.BuiltinDefaults()
+	return &ControllerConfig{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		TransportAdapter: TransportAdapter{
+			Repository: defaults.AdapterRepository,
+			Version:    defaults.AdapterVersion,
+		},
+		SecurityProfile: SecurityProfile{
+			SandboxRuntimeClassName: transportadapter.DefaultSandboxRuntimeClassName,
+		},
+		Requeue: Requeue{
+			NotReady:     metav1.Duration{Duration: defaultNotReadyInterval},
+			ToolsCheck:   metav1.Duration{Duration: defaultToolsCheckInterval},
+			ToolsRecheck: metav1.Duration{Duration: defaultToolsRecheckInterval},
+		},
+		FeatureGates: maps.Clone(featureGates),
+	}
+}
+
+// Parse parses a configuration file. The settings of the file override the settings of
+// base, which holds the defaults and the settings of the command line flags; its runners
+// are merged after the runners of base. Unknown fields and invalid settings are rejected.
+func Parse(data []byte, base *ControllerConfig) (*ControllerConfig, error) {
+	cfg := base.DeepCopy()
+	cfg.APIVersion = ""
+	cfg.Kind = ""
+	cfg.Runners = nil
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the controller configuration: %w", err)
+	}
+	cfg.Runners = append(slices.Clone(base.Runners), cfg.Runners...)
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid controller configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// Validate checks the settings of the configuration.
+func (c *ControllerConfig) Validate() error {
+	var errs field.ErrorList
+	if c.APIVersion != APIVersion {
+		errs = append(errs, field.NotSupported(field.NewPath("apiVersion"), c.APIVersion, []string{APIVersion}))
+	}
+	if c.Kind != Kind {
+		errs = append(errs, field.NotSupported(field.NewPath("kind"), c.Kind, []string{Kind}))
+	}
+
+	adapterPath := field.NewPath("transportAdapter")
+	if c.TransportAdapter.Repository == "" {
+		errs = append(errs, field.Required(adapterPath.Child("repository"), ""))
+	}
+	if err := transportadapter.ValidateVersion(c.TransportAdapter.Version); err != nil {
+		errs = append(errs, field.Invalid(adapterPath.Child("version"), c.TransportAdapter.Version, err.Error()))
+	}
+
+	for _, runner := range c.RegisteredRunners() {
+		if err := transportadapter.CheckRunner(runner); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("runners").Key(runner.Name), runner.Name, err.Error()))
+		}
+	}
+
+	profile := c.SecurityProfile.Default
+	if err := transportadapter.CheckSecurityProfile(&kagentdevv1alpha1.MCPServer{}, profile); err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("securityProfile", "default"), profile, err.Error()))
+	}
+
+	requeuePath := field.NewPath("requeue")
+	if c.Requeue.NotReady.Duration <= 0 {
+		errs = append(errs, field.Invalid(requeuePath.Child("notReady"), c.Requeue.NotReady.Duration.String(),
+			"must be positive"))
+	}
+	if c.Requeue.ToolsCheck.Duration <= 0 {
+		errs = append(errs, field.Invalid(requeuePath.Child("toolsCheck"), c.Requeue.ToolsCheck.Duration.String(),
+			"must be positive"))
+	}
+	if c.Requeue.ToolsRecheck.Duration <= 0 {
+		errs = append(errs, field.Invalid(requeuePath.Child("toolsRecheck"), c.Requeue.ToolsRecheck.Duration.String(),
+			"must be positive"))
+	}
+
+	gates := make([]string, 0, len(featureGates))
+	for gate := range featureGates {
+		gates = append(gates, gate)
+	}
+	sort.Strings(gates)
+	for gate := range c.FeatureGates {
+		if _, ok := featureGates[gate]; !ok {
+			errs = append(errs, field.NotSupported(field.NewPath("featureGates").Key(gate), gate, gates))
+		}
+	}
+	return errs.ToAggregate()
+}
+
+// Enabled reports whether the feature gate is enabled.
+func (c *ControllerConfig) Enabled(gate string) bool {
+	if enabled, ok := c.FeatureGates[gate]; ok {
+		return enabled
+	}
+	return featureGates[gate]
+}
+
+// TranslationDefaults returns the defaults of the transport adapter translation.
+func (c *ControllerConfig) TranslationDefaults() transportadapter.Defaults {
+	return transportadapter.Defaults{
+		AdapterRepository:   c.TransportAdapter.Repository,
+		AdapterVersion:      c.TransportAdapter.Version,
+		Runners:             c.RegisteredRunners(),
+		KgatewayAppProtocol: c.Enabled(KgatewayMCPAppProtocol),
+	}
+}
+
+// RegisteredRunners returns the built-in runners merged with the runners of the
+// configuration.
+func (c *ControllerConfig) RegisteredRunners() []transportadapter.Runner {
+	return transportadapter.MergeRunners(transportadapter.BuiltinRunners(), c.Runners)
+}
+
+// DeepCopy returns a copy of the configuration. The runner slice is cloned, while the
+// slices and maps of each runner are shared, since they are never modified in place.
+func (c *ControllerConfig) DeepCopy() *ControllerConfig {
+	out := *c
+	out.Runners = slices.Clone(c.Runners)
+	out.FeatureGates = maps.Clone(c.FeatureGates)
+	return &out
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/kagent-dev/kmcp/pkg/controller/transportadapter"
+)
+
+const validConfig = `apiVersion: kmcp.kagent.dev/v1alpha1
+kind: ControllerConfig
+transportAdapter:
+  version: 1.0.0
+runners:
+  - name: npx
+    image: registry.example.com/node:24
+  - name: pipx
+    image: registry.example.com/pipx:1.7
+    ecosystem: pypi
+securityProfile:
+  default: restricted
+requeue:
+  notReady: 30s
+featureGates:
+  KgatewayMCPAppProtocol: false
+`
+
+func TestParse(t *testing.T) {
+	base := Default()
+	base.SecurityProfile.SandboxRuntimeClassName = "kata"
+	base.Runners = []transportadapter.Runner{{Name: "uvx", Image: "registry.example.com/uv:debian"}}
+
+	cfg, err := Parse([]byte(validConfig), base)
+	if err != nil {
+		t.Fatalf("failed to parse the configuration: %v", err)
+	}
+	if cfg.TransportAdapter.Version != "1.0.0" || cfg.TransportAdapter.Repository != base.TransportAdapter.Repository {
+		t.Errorf("unexpected transport adapter: %+v", cfg.TransportAdapter)
+	}
+	images := map[string]string{}
+	for _, runner := range cfg.RegisteredRunners() {
+		images[runner.Name] = runner.Image
+	}
+	if images["npx"] != "registry.example.com/node:24" || images["uvx"] != "registry.example.com/uv:debian" ||
+		images["pipx"] != "registry.example.com/pipx:1.7" || images["deno"] != "denoland/deno:debian" {
+		t.Errorf("expected the runners of the file merged after the runners of the base, got %v", images)
+	}
+	if cfg.SecurityProfile.Default != kagentdevv1alpha1.SecurityProfileRestricted ||
+		cfg.SecurityProfile.SandboxRuntimeClassName != "kata" {
+		t.Errorf("expected the file to override the base security profile, got %+v", cfg.SecurityProfile)
+	}
+	if cfg.Requeue.NotReady.Duration != 30*time.Second || cfg.Requeue.ToolsCheck.Duration != defaultToolsCheckInterval {
+		t.Errorf("unexpected requeue intervals: %+v", cfg.Requeue)
+	}
+	if cfg.Enabled(KgatewayMCPAppProtocol) || cfg.TranslationDefaults().KgatewayAppProtocol {
+		t.Errorf("expected the %s feature gate to be disabled", KgatewayMCPAppProtocol)
+	}
+	if !base.Enabled(KgatewayMCPAppProtocol) {
+		t.Errorf("expected the base configuration to be left unchanged")
+	}
+}
+
+func TestParseRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "unknown version",
+			config:  "apiVersion: kmcp.kagent.dev/v2\nkind: ControllerConfig\n",
+			wantErr: "apiVersion: Unsupported value",
+		},
+		{
+			name:    "unknown field",
+			config:  "apiVersion: kmcp.kagent.dev/v1alpha1\nkind: ControllerConfig\nrequeueInterval: 5s\n",
+			wantErr: `unknown field "requeueInterval"`,
+		},
+		{
+			name: "invalid adapter version",
+			config: "apiVersion: kmcp.kagent.dev/v1alpha1\nkind: ControllerConfig\n" +
+				"transportAdapter:\n  version: 1.0.0;latest\n",
+			wantErr: "transportAdapter.version: Invalid value",
+		},
+		{
+			name: "runner without image",
+			config: "apiVersion: kmcp.kagent.dev/v1alpha1\nkind: ControllerConfig\n" +
+				"runners:\n  - name: pipx\n",
+			wantErr: "runners[pipx]: Invalid value",
+		},
+		{
+			name: "invalid security profile",
+			config: "apiVersion: kmcp.kagent.dev/v1alpha1\nkind: ControllerConfig\n" +
+				"securityProfile:\n  default: privileged\n",
+			wantErr: "securityProfile.default: Invalid value",
+		},
+		{
+			name: "negative requeue interval",
+			config: "apiVersion: kmcp.kagent.dev/v1alpha1\nkind: ControllerConfig\n" +
+				"requeue:\n  notReady: -5s\n",
+			wantErr: "requeue.notReady: Invalid value",
+		},
+		{
+			name: "zero tools recheck interval",
+			config: "apiVersion: kmcp.kagent.dev/v1alpha1\nkind: ControllerConfig\n" +
+				"requeue:\n  toolsRecheck: 0s\n",
+			wantErr: "requeue.toolsRecheck: Invalid value",
+		},
+		{
+			name: "unknown feature gate",
+			config: "apiVersion: kmcp.kagent.dev/v1alpha1\nkind: ControllerConfig\n" +
+				"featureGates:\n  Teleport: true\n",
+			wantErr: "featureGates[Teleport]: Unsupported value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.config), Default())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWatcherReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(validConfig), 0o644); err != nil {
+		t.Fatalf("failed to write the configuration: %v", err)
+	}
+	watcher, err := NewWatcher(path, Default())
+	if err != nil {
+		t.Fatalf("failed to load the configuration: %v", err)
+	}
+	changes := make(chan *ControllerConfig, 10)
+	watcher.OnChange(func(cfg *ControllerConfig) { changes <- cfg })
+	<-changes
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			t.Errorf("failed to watch the configuration: %v", err)
+		}
+	}()
+
+	// An invalid file is rejected and the previous configuration kept; the valid file
+	// written next is applied
+	deadline := time.After(10 * time.Second)
+	for _, content := range []string{"kind: [", strings.Replace(validConfig, "30s", "1m", 1)} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write the configuration: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	for {
+		select {
+		case cfg := <-changes:
+			if cfg.Requeue.NotReady.Duration != time.Minute {
+				t.Fatalf("expected the reloaded configuration, got %+v", cfg.Requeue)
+			}
+			if current := watcher.Current(); current != cfg {
+				t.Errorf("expected the current configuration to be the reloaded one")
+			}
+			return
+		case <-deadline:
+			t.Fatalf("the configuration was not reloaded, current: %+v", watcher.Current().Requeue)
+		}
+	}
+}
+
+func TestNewWatcherRejectsInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("apiVersion: v1\nkind: ConfigMap\n"), 0o644); err != nil {
+		t.Fatalf("failed to write the configuration: %v", err)
+	}
+	if _, err := NewWatcher(path, Default()); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("expected an error naming the file, got %v", err)
+	}
+}
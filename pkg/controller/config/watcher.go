@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var log = ctrl.Log.WithName("controller-config")
+
+// Watcher holds the configuration of the controller and reloads it when its file
+// changes. A file that fails to load is rejected and the previous configuration is kept.
+type Watcher struct {
+	path    string
+	base    *ControllerConfig
+	current atomic.Pointer[ControllerConfig]
+
+	mu        sync.Mutex
+	data      []byte
+	callbacks []func(*ControllerConfig)
+}
+
+// NewWatcher loads the configuration file at path over base, which holds the defaults
+// and the settings of the command line flags. Without a path, the configuration is base.
+func NewWatcher(path string, base *ControllerConfig) (*Watcher, error) {
+	w := &Watcher{path: path, base: base}
+	if path == "" {
+		if err := base.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid controller configuration: %w", err)
+		}
+		w.current.Store(base)
+		return w, nil
+	}
+	if _, err := w.reload(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Current returns the current configuration, or the default configuration on a nil
+// Watcher.
+func (w *Watcher) Current() *ControllerConfig {
+	if w == nil {
+		return Default()
+	}
+	return w.current.Load()
+}
+
+// OnChange registers a callback invoked with the current configuration, and then with
+// every configuration reloaded from the file.
+func (w *Watcher) OnChange(callback func(*ControllerConfig)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callbacks = append(w.callbacks, callback)
+	callback(w.current.Load())
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable: every replica reloads its
+// configuration.
+func (w *Watcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start watches the directory of the configuration file until the context is done. The
+// directory is watched rather than the file, since ConfigMap volumes replace their
+// files by swapping a symbolic link.
+func (w *Watcher) Start(ctx context.Context) error {
+	if w.path == "" {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close() //nolint:errcheck // the watch is over
+	if err := watcher.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to watch the controller configuration: %w", err)
+	}
+
+	log.Info("Watching the controller configuration", "path", w.path)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op.Has(fsnotify.Chmod) {
+				continue
+			}
+			changed, err := w.reload()
+			if err != nil {
+				log.Error(err, "Rejected the controller configuration, keeping the previous one", "path", w.path)
+			} else if changed {
+				log.Info("Reloaded the controller configuration", "path", w.path)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error(err, "Failed to watch the controller configuration")
+		}
+	}
+}
+
+// reload loads the configuration file when its content changed and notifies the
+// callbacks. It reports whether a new configuration was loaded.
+func (w *Watcher) reload() (bool, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the controller configuration: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.data != nil && bytes.Equal(data, w.data) {
+		return false, nil
+	}
+	cfg, err := Parse(data, w.base)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", w.path, err)
+	}
+	w.data = data
+	w.current.Store(cfg)
+	for _, callback := range w.callbacks {
+		callback(cfg)
+	}
+	return true, nil
+}
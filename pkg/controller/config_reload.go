@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/kagent-dev/kmcp/pkg/controller/config"
+)
+
+// configReloads returns a source enqueuing all resources of the list type whenever the
+// controller configuration is reloaded, so that resources which are ready and would
+// otherwise not be reconciled again pick up the new configuration.
+func configReloads(
+	watcher *config.Watcher,
+	c client.Reader,
+	newList func() client.ObjectList,
+) source.Source {
+	// Reloads arriving while one is pending are coalesced, a single listing covers them
+	reloads := make(chan event.GenericEvent, 1)
+	initial := true
+	watcher.OnChange(func(*config.ControllerConfig) {
+		// The current configuration is applied by the initial reconcile of every resource
+		if initial {
+			initial = false
+			return
+		}
+		select {
+		case reloads <- event.GenericEvent{Object: &metav1.PartialObjectMetadata{}}:
+		default:
+		}
+	})
+	return source.Channel(reloads, handler.EnqueueRequestsFromMapFunc(
+		func(ctx context.Context, _ client.Object) []reconcile.Request {
+			return listRequests(ctx, c, newList())
+		}))
+}
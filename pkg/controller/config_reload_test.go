@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/kagent-dev/kmcp/pkg/controller/config"
+)
+
+var _ = ginkgo.Describe("Controller configuration reloads", func() {
+	ginkgo.It("should enqueue every MCPServer when the configuration is reloaded", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		ginkgo.DeferCleanup(cancel)
+
+		server := newBenchmarkServer("test-config-reload")
+		gomega.Expect(k8sClient.Create(ctx, server)).To(gomega.Succeed())
+		ginkgo.DeferCleanup(func() {
+			gomega.Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(), server))).To(gomega.Succeed())
+		})
+
+		const header = "apiVersion: " + config.APIVersion + "\nkind: " + config.Kind + "\n"
+		path := filepath.Join(ginkgo.GinkgoT().TempDir(), "config.yaml")
+		gomega.Expect(os.WriteFile(path, []byte(header+"requeue:\n  notReady: 30s\n"), 0o644)).To(gomega.Succeed())
+		watcher, err := config.NewWatcher(path, config.Default())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+		ginkgo.DeferCleanup(queue.ShutDown)
+		src := configReloads(watcher, k8sClient, func() client.ObjectList {
+			return &kagentdevv1alpha1.MCPServerList{}
+		})
+		gomega.Expect(src.Start(ctx, queue)).To(gomega.Succeed())
+		go func() {
+			defer ginkgo.GinkgoRecover()
+			gomega.Expect(watcher.Start(ctx)).To(gomega.Succeed())
+		}()
+
+		ginkgo.By("not enqueuing anything for the configuration loaded at startup")
+		gomega.Consistently(queue.Len, 200*time.Millisecond).Should(gomega.BeZero())
+
+		ginkgo.By("enqueuing the MCPServer once the configuration changes")
+		gomega.Expect(os.WriteFile(path, []byte(header+"requeue:\n  notReady: 1m\n"), 0o644)).To(gomega.Succeed())
+		gomega.Eventually(queue.Len, 10*time.Second).Should(gomega.BeNumerically(">", 0))
+		requests := make(map[reconcile.Request]bool)
+		for queue.Len() > 0 {
+			request, _ := queue.Get()
+			requests[request] = true
+			queue.Done(request)
+		}
+		gomega.Expect(requests).To(gomega.HaveKey(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(server)}))
+	})
+})
@@ -0,0 +1,313 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/kagent-dev/kmcp/pkg/controller/config"
+	"github.com/kagent-dev/kmcp/pkg/controller/transportadapter"
+)
+
+// MCPGatewayReconciler reconciles a MCPGateway object
+type MCPGatewayReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Config holds the controller configuration. The default configuration is used
+	// when nil.
+	Config *config.Watcher
+	// Options configure the concurrency and the retries of the controller.
+	Options ReconcileOptions
+	// Scope selects the MCPGateways reconciled by this controller. All MCPGateways are
+	// reconciled by default.
+	Scope Scope
+}
+
+// +kubebuilder:rbac:groups=kagent.dev,resources=mcpgateways,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kagent.dev,resources=mcpgateways/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kagent.dev,resources=mcpgateways/finalizers,verbs=update
+
+// Reconcile runs the shared gateway of an MCPGateway and routes it to the selected MCPServers.
+func (r *MCPGatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	gateway := &kagentdevv1alpha1.MCPGateway{}
+	if err := r.Get(ctx, req.NamespacedName, gateway); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// MCPGateways of other namespaces or shards are left to other controllers
+	if inScope, err := r.Scope.Includes(ctx, r.Client, gateway); err != nil || !inScope {
+		return ctrl.Result{}, err
+	}
+
+	ctx, span := startSpan(ctx, "MCPGateway.Reconcile", gateway)
+	defer func() { endSpan(span, err) }()
+
+	if err := r.validateMCPGateway(ctx, gateway); err != nil {
+		log.FromContext(ctx).Error(err, "Invalid MCPGateway")
+		r.reconcileGatewayStatus(ctx, gateway, nil, err, nil)
+		return ctrl.Result{}, nil
+	}
+
+	servers, err := r.selectServers(ctx, gateway)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	cfg := r.Config.Current()
+	t := transportadapter.NewGatewayTranslator(r.Scheme, cfg.TranslationDefaults())
+	outputs, err := t.TranslateGatewayOutputs(ctx, gateway, servers)
+	if err == nil {
+		for _, output := range outputs {
+			if err = upsertOutput(ctx, r.Client, output); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to reconcile MCPGateway outputs")
+		r.reconcileGatewayStatus(ctx, gateway, servers, nil, err)
+		return ctrl.Result{}, err
+	}
+
+	r.reconcileGatewayStatus(ctx, gateway, servers, nil, nil)
+
+	// Readiness changes of the gateway Deployment trigger a reconcile, so a gateway that is
+	// not ready is only checked again after a long interval as a safety net
+	if !meta.IsStatusConditionTrue(gateway.Status.Conditions, string(kagentdevv1alpha1.MCPGatewayConditionReady)) {
+		return ctrl.Result{RequeueAfter: cfg.Requeue.NotReady.Duration}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MCPGatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&kagentdevv1alpha1.MCPGateway{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&appsv1.Deployment{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Owns(&corev1.Service{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Owns(&corev1.ConfigMap{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		// Servers joining, leaving or changing health update the routes and status of the gateways
+		Watches(&kagentdevv1alpha1.MCPServer{}, handler.EnqueueRequestsFromMapFunc(r.gatewaysForServer)).
+		WithOptions(r.Options.controllerOptions())
+	if r.Scope.selectsNamespaces() {
+		// Namespaces whose labels changed may come in or out of scope
+		b = b.Watches(&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(requestsInNamespace(r.Client, func() client.ObjectList {
+				return &kagentdevv1alpha1.MCPGatewayList{}
+			})),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}))
+	}
+	if r.Config != nil {
+		b = b.WatchesRawSource(configReloads(r.Config, r.Client, func() client.ObjectList {
+			return &kagentdevv1alpha1.MCPGatewayList{}
+		}))
+	}
+	return b.Named("mcpgateway").Complete(r)
+}
+
+// gatewaysForServer enqueues all MCPGateways in the namespace of an MCPServer. Gateways
+// that no longer select a server must drop it as well, so the selectors are not checked.
+func (r *MCPGatewayReconciler) gatewaysForServer(ctx context.Context, obj client.Object) []reconcile.Request {
+	gateways := &kagentdevv1alpha1.MCPGatewayList{}
+	if err := r.List(ctx, gateways, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list MCPGateways")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(gateways.Items))
+	for _, gateway := range gateways.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: gateway.Name, Namespace: gateway.Namespace},
+		})
+	}
+	return requests
+}
+
+// selectServers returns the MCPServers selected by the gateway, sorted by name.
+func (r *MCPGatewayReconciler) selectServers(
+	ctx context.Context,
+	gateway *kagentdevv1alpha1.MCPGateway,
+) ([]kagentdevv1alpha1.MCPServer, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&gateway.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+
+	servers := &kagentdevv1alpha1.MCPServerList{}
+	if err := r.List(
+		ctx,
+		servers,
+		client.InNamespace(gateway.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return nil, err
+	}
+
+	items := make([]kagentdevv1alpha1.MCPServer, 0, len(servers.Items))
+	for _, server := range servers.Items {
+		if server.DeletionTimestamp != nil {
+			continue
+		}
+		// Template instances are routed with their expanded spec; instances that fail to
+		// expand are reported on their own status and left out of the gateway
+		expanded, err := expandServerTemplate(ctx, r.Client, &server)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Skipping MCPServer", "mcpserver", server.Name)
+			continue
+		}
+		items = append(items, *expanded)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+// validateMCPGateway checks the selector of the gateway and that the gateway does not
+// collide with the resources of an MCPServer.
+func (r *MCPGatewayReconciler) validateMCPGateway(ctx context.Context, gateway *kagentdevv1alpha1.MCPGateway) error {
+	if _, err := metav1.LabelSelectorAsSelector(&gateway.Spec.Selector); err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+
+	server := &kagentdevv1alpha1.MCPServer{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(gateway), server)
+	if err == nil {
+		return fmt.Errorf("MCPServer %s exists in namespace %s, the MCPGateway must use another name",
+			gateway.Name, gateway.Namespace)
+	}
+	return client.IgnoreNotFound(err)
+}
+
+func (r *MCPGatewayReconciler) reconcileGatewayStatus(
+	ctx context.Context,
+	gateway *kagentdevv1alpha1.MCPGateway,
+	servers []kagentdevv1alpha1.MCPServer,
+	validationErr error,
+	reconcileErr error,
+) {
+	gateway.Status.ObservedGeneration = gateway.Generation
+
+	gateway.Status.Servers = make([]kagentdevv1alpha1.MCPGatewayServerStatus, 0, len(servers))
+	for _, server := range servers {
+		serverStatus := kagentdevv1alpha1.MCPGatewayServerStatus{Name: server.Name}
+		if ready := meta.FindStatusCondition(
+			server.Status.Conditions,
+			string(kagentdevv1alpha1.MCPServerConditionReady),
+		); ready != nil {
+			serverStatus.Ready = ready.Status == metav1.ConditionTrue
+			serverStatus.Message = ready.Message
+		}
+		gateway.Status.Servers = append(gateway.Status.Servers, serverStatus)
+	}
+
+	switch {
+	case validationErr != nil:
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionAccepted, false,
+			kagentdevv1alpha1.MCPGatewayReasonInvalidConfig, validationErr.Error())
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionProgrammed, false,
+			kagentdevv1alpha1.MCPGatewayReasonDeploymentFailed, "Configuration validation failed")
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionReady, false,
+			kagentdevv1alpha1.MCPGatewayReasonPodsNotReady, "Configuration validation failed")
+	case reconcileErr != nil:
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionAccepted, true,
+			kagentdevv1alpha1.MCPGatewayReasonAccepted, "MCPGateway configuration is valid")
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionProgrammed, false,
+			kagentdevv1alpha1.MCPGatewayReasonDeploymentFailed, reconcileErr.Error())
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionReady, false,
+			kagentdevv1alpha1.MCPGatewayReasonPodsNotReady, "Resources failed to be created")
+	default:
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionAccepted, true,
+			kagentdevv1alpha1.MCPGatewayReasonAccepted, "MCPGateway configuration is valid")
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionProgrammed, true,
+			kagentdevv1alpha1.MCPGatewayReasonProgrammed, "All resources created successfully")
+		r.checkGatewayReadyCondition(ctx, gateway)
+	}
+
+	// Update the status, unless it is unchanged
+	current := &kagentdevv1alpha1.MCPGateway{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(gateway), current); err == nil &&
+		equality.Semantic.DeepEqual(current.Status, gateway.Status) {
+		return
+	}
+	if err := r.Status().Update(ctx, gateway); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to update MCPGateway status")
+	}
+}
+
+// checkGatewayReadyCondition checks if the gateway Deployment has all replicas available.
+func (r *MCPGatewayReconciler) checkGatewayReadyCondition(ctx context.Context, gateway *kagentdevv1alpha1.MCPGateway) {
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(gateway), deployment); err != nil {
+		message := fmt.Sprintf("Error getting deployment: %s", err.Error())
+		if client.IgnoreNotFound(err) == nil {
+			message = "Deployment not found"
+		}
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionReady, false,
+			kagentdevv1alpha1.MCPGatewayReasonPodsNotReady, message)
+		return
+	}
+
+	available := deployment.Status.AvailableReplicas
+	if available > 0 && available == deployment.Status.Replicas {
+		setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionReady, true,
+			kagentdevv1alpha1.MCPGatewayReasonAvailable, "Deployment is ready and all pods are running")
+		return
+	}
+	setGatewayCondition(gateway, kagentdevv1alpha1.MCPGatewayConditionReady, false,
+		kagentdevv1alpha1.MCPGatewayReasonNotAvailable,
+		fmt.Sprintf("Deployment not ready: %d/%d replicas available", available, deployment.Status.Replicas))
+}
+
+// setGatewayCondition sets the given condition on the MCPGateway status.
+func setGatewayCondition(
+	gateway *kagentdevv1alpha1.MCPGateway,
+	conditionType kagentdevv1alpha1.MCPGatewayConditionType,
+	ok bool,
+	reason kagentdevv1alpha1.MCPGatewayConditionReason,
+	message string,
+) {
+	status := metav1.ConditionTrue
+	if !ok {
+		status = metav1.ConditionFalse
+	}
+	meta.SetStatusCondition(&gateway.Status.Conditions, metav1.Condition{
+		Type:               string(conditionType),
+		Status:             status,
+		Reason:             string(reason),
+		Message:            message,
+		ObservedGeneration: gateway.Generation,
+	})
+}
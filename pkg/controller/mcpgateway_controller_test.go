@@ -0,0 +1,150 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+var _ = ginkgo.Describe("MCPGateway Controller", func() {
+	ginkgo.Context("When reconciling a gateway", func() {
+		ctx := context.Background()
+
+		newServer := func(name string, labels map[string]string) *kagentdevv1alpha1.MCPServer {
+			return &kagentdevv1alpha1.MCPServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					Labels:    labels,
+				},
+				Spec: kagentdevv1alpha1.MCPServerSpec{
+					TransportType: kagentdevv1alpha1.TransportTypeStdio,
+					Deployment: kagentdevv1alpha1.MCPServerDeployment{
+						Image: "test-image:latest",
+						Port:  3000,
+						Cmd:   "npx",
+					},
+				},
+			}
+		}
+
+		ginkgo.It("should route to the selected servers and list them in the status", func() {
+			ginkgo.By("Creating a selected and an unselected MCPServer")
+			selected := newServer("gw-selected", map[string]string{"team": "search"})
+			unselected := newServer("gw-unselected", map[string]string{"team": "billing"})
+			gomega.Expect(k8sClient.Create(ctx, selected)).To(gomega.Succeed())
+			gomega.Expect(k8sClient.Create(ctx, unselected)).To(gomega.Succeed())
+
+			ginkgo.By("Creating an MCPGateway selecting the search team")
+			gatewayName := "test-gateway"
+			gateway := &kagentdevv1alpha1.MCPGateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      gatewayName,
+					Namespace: "default",
+				},
+				Spec: kagentdevv1alpha1.MCPGatewaySpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"team": "search"},
+					},
+					RoutingMode: kagentdevv1alpha1.MCPGatewayRoutingModeFederated,
+				},
+			}
+			gomega.Expect(k8sClient.Create(ctx, gateway)).To(gomega.Succeed())
+
+			ginkgo.By("Reconciling the MCPGateway")
+			controllerReconciler := &MCPGatewayReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			typeNamespacedName := types.NamespacedName{Name: gatewayName, Namespace: "default"}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			ginkgo.By("Verifying the gateway resources were created")
+			gomega.Expect(k8sClient.Get(ctx, typeNamespacedName, &appsv1.Deployment{})).To(gomega.Succeed())
+			gomega.Expect(k8sClient.Get(ctx, typeNamespacedName, &corev1.Service{})).To(gomega.Succeed())
+			configMap := &corev1.ConfigMap{}
+			gomega.Expect(k8sClient.Get(ctx, typeNamespacedName, configMap)).To(gomega.Succeed())
+			gomega.Expect(configMap.Data["local.yaml"]).To(
+				gomega.ContainSubstring("gw-selected.default.svc.cluster.local"))
+			gomega.Expect(configMap.Data["local.yaml"]).NotTo(
+				gomega.ContainSubstring("gw-unselected"))
+
+			ginkgo.By("Verifying the status lists the attached servers")
+			updated := &kagentdevv1alpha1.MCPGateway{}
+			gomega.Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(gomega.Succeed())
+			gomega.Expect(updated.Status.Servers).To(gomega.HaveLen(1))
+			gomega.Expect(updated.Status.Servers[0].Name).To(gomega.Equal("gw-selected"))
+			gomega.Expect(updated.Status.Servers[0].Ready).To(gomega.BeFalse())
+			gomega.Expect(meta.IsStatusConditionTrue(
+				updated.Status.Conditions,
+				string(kagentdevv1alpha1.MCPGatewayConditionProgrammed),
+			)).To(gomega.BeTrue())
+
+			// Cleanup
+			gomega.Expect(k8sClient.Delete(ctx, gateway)).To(gomega.Succeed())
+			gomega.Expect(k8sClient.Delete(ctx, selected)).To(gomega.Succeed())
+			gomega.Expect(k8sClient.Delete(ctx, unselected)).To(gomega.Succeed())
+		})
+
+		ginkgo.It("should reject a gateway that shares its name with an MCPServer", func() {
+			name := "gw-conflict"
+			server := newServer(name, nil)
+			gomega.Expect(k8sClient.Create(ctx, server)).To(gomega.Succeed())
+			gateway := &kagentdevv1alpha1.MCPGateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+				},
+			}
+			gomega.Expect(k8sClient.Create(ctx, gateway)).To(gomega.Succeed())
+
+			controllerReconciler := &MCPGatewayReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			typeNamespacedName := types.NamespacedName{Name: name, Namespace: "default"}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			updated := &kagentdevv1alpha1.MCPGateway{}
+			gomega.Expect(k8sClient.Get(ctx, typeNamespacedName, updated)).To(gomega.Succeed())
+			accepted := meta.FindStatusCondition(
+				updated.Status.Conditions,
+				string(kagentdevv1alpha1.MCPGatewayConditionAccepted),
+			)
+			gomega.Expect(accepted).NotTo(gomega.BeNil())
+			gomega.Expect(accepted.Status).To(gomega.Equal(metav1.ConditionFalse))
+			gomega.Expect(accepted.Reason).To(gomega.Equal(string(kagentdevv1alpha1.MCPGatewayReasonInvalidConfig)))
+
+			// Cleanup
+			gomega.Expect(k8sClient.Delete(ctx, gateway)).To(gomega.Succeed())
+			gomega.Expect(k8sClient.Delete(ctx, server)).To(gomega.Succeed())
+		})
+	})
+})
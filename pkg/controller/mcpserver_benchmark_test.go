@@ -0,0 +1,212 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// writeCountingClient returns a client counting the writes made through it.
+func writeCountingClient(writes *atomic.Int64) client.Client {
+	kube, err := client.NewWithWatch(cfg, client.Options{Scheme: k8sClient.Scheme()})
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	return interceptor.NewClient(kube, interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			writes.Add(1)
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			writes.Add(1)
+			return c.Update(ctx, obj, opts...)
+		},
+		Patch: func(
+			ctx context.Context,
+			c client.WithWatch,
+			obj client.Object,
+			patch client.Patch,
+			opts ...client.PatchOption,
+		) error {
+			writes.Add(1)
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+		SubResourceUpdate: func(
+			ctx context.Context,
+			c client.Client,
+			subResource string,
+			obj client.Object,
+			opts ...client.SubResourceUpdateOption,
+		) error {
+			writes.Add(1)
+			return c.SubResource(subResource).Update(ctx, obj, opts...)
+		},
+	})
+}
+
+func newBenchmarkServer(name string) *kagentdevv1alpha1.MCPServer {
+	return &kagentdevv1alpha1.MCPServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: kagentdevv1alpha1.MCPServerSpec{
+			Deployment: kagentdevv1alpha1.MCPServerDeployment{
+				Image: "docker.io/mcp/everything",
+				Port:  3000,
+				Cmd:   "npx",
+				Args:  []string{"-y", "@modelcontextprotocol/server-filesystem", "/"},
+			},
+			TransportType: "stdio",
+		},
+	}
+}
+
+var _ = ginkgo.Describe("MCPServer Controller writes", func() {
+	ctx := context.Background()
+
+	ginkgo.It("should not write an unchanged MCPServer again", func() {
+		server := newBenchmarkServer("test-unchanged-writes")
+		gomega.Expect(k8sClient.Create(ctx, server)).To(gomega.Succeed())
+		ginkgo.DeferCleanup(func() {
+			gomega.Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, server))).To(gomega.Succeed())
+		})
+
+		var writes atomic.Int64
+		reconciler := &MCPServerReconciler{
+			Client: writeCountingClient(&writes),
+			Scheme: k8sClient.Scheme(),
+		}
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(server)}
+
+		ginkgo.By("creating the outputs and status on the first reconcile")
+		_, err := reconciler.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(writes.Load()).To(gomega.BeNumerically(">", 0))
+
+		ginkgo.By("writing nothing on the next reconcile")
+		writes.Store(0)
+		result, err := reconciler.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(writes.Load()).To(gomega.BeZero())
+
+		ginkgo.By("not polling the workload that is not ready")
+		gomega.Expect(result.RequeueAfter).To(gomega.BeNumerically(">", time.Minute))
+
+		ginkgo.By("updating the outputs once the spec changes")
+		deployment := &appsv1.Deployment{}
+		gomega.Expect(k8sClient.Get(ctx, request.NamespacedName, deployment)).To(gomega.Succeed())
+		hash := deployment.Annotations[specHashAnnotation]
+		gomega.Expect(hash).NotTo(gomega.BeEmpty())
+
+		updated := &kagentdevv1alpha1.MCPServer{}
+		gomega.Expect(k8sClient.Get(ctx, request.NamespacedName, updated)).To(gomega.Succeed())
+		updated.Spec.Deployment.Args = append(updated.Spec.Deployment.Args, "/tmp")
+		gomega.Expect(k8sClient.Update(ctx, updated)).To(gomega.Succeed())
+		_, err = reconciler.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		gomega.Expect(k8sClient.Get(ctx, request.NamespacedName, deployment)).To(gomega.Succeed())
+		gomega.Expect(deployment.Annotations[specHashAnnotation]).NotTo(gomega.Equal(hash))
+	})
+
+	ginkgo.It("should restore an output edited by hand", func() {
+		server := newBenchmarkServer("test-edited-output")
+		gomega.Expect(k8sClient.Create(ctx, server)).To(gomega.Succeed())
+		ginkgo.DeferCleanup(func() {
+			gomega.Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, server))).To(gomega.Succeed())
+		})
+
+		reconciler := &MCPServerReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(server)}
+		_, err := reconciler.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		ginkgo.By("editing the Deployment without changing its spec hash")
+		deployment := &appsv1.Deployment{}
+		gomega.Expect(k8sClient.Get(ctx, request.NamespacedName, deployment)).To(gomega.Succeed())
+		image := deployment.Spec.Template.Spec.Containers[0].Image
+		deployment.Spec.Template.Spec.Containers[0].Image = "edited-image:latest"
+		gomega.Expect(k8sClient.Update(ctx, deployment)).To(gomega.Succeed())
+
+		ginkgo.By("restoring the Deployment on the next reconcile")
+		_, err = reconciler.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(k8sClient.Get(ctx, request.NamespacedName, deployment)).To(gomega.Succeed())
+		gomega.Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(gomega.Equal(image))
+	})
+
+	ginkgo.It("should measure the writes of steady MCPServers", ginkgo.Label("measurement"), func() {
+		const servers = 20
+
+		var writes atomic.Int64
+		reconciler := &MCPServerReconciler{
+			Client: writeCountingClient(&writes),
+			Scheme: k8sClient.Scheme(),
+		}
+		var requests []reconcile.Request
+		for i := range servers {
+			server := newBenchmarkServer(fmt.Sprintf("test-benchmark-%d", i))
+			gomega.Expect(k8sClient.Create(ctx, server)).To(gomega.Succeed())
+			ginkgo.DeferCleanup(func() {
+				gomega.Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, server))).To(gomega.Succeed())
+			})
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      server.Name,
+				Namespace: server.Namespace,
+			}})
+		}
+
+		reconcileAll := func() {
+			for _, request := range requests {
+				_, err := reconciler.Reconcile(ctx, request)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			}
+		}
+
+		experiment := gmeasure.NewExperiment("Reconciling MCPServers")
+		ginkgo.AddReportEntry(experiment.Name, experiment)
+
+		experiment.MeasureDuration("initial reconcile", reconcileAll)
+		experiment.RecordValue("initial writes", float64(writes.Load()), gmeasure.Units("writes"))
+
+		experiment.SampleDuration("steady reconcile", func(int) {
+			writes.Store(0)
+			reconcileAll()
+			experiment.RecordValue("steady writes", float64(writes.Load()), gmeasure.Units("writes"))
+		}, gmeasure.SamplingConfig{N: 5})
+
+		steady := experiment.GetStats("steady writes")
+		gomega.Expect(steady.FloatFor(gmeasure.StatMax)).To(gomega.BeZero())
+	})
+})
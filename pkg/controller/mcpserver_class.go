@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/kagent-dev/kmcp/pkg/controller/transportadapter"
+)
+
+// errServerClass marks the errors caused by the MCPServerClass of an MCPServer, which are
+// reported as configuration errors on the Accepted condition.
+var errServerClass = errors.New("invalid MCPServerClass")
+
+// applyServerClass returns a copy of the MCPServer with the defaults of its MCPServerClass
+// merged in. The class is the one named by spec.className, or else the default class.
+func applyServerClass(
+	ctx context.Context,
+	c client.Reader,
+	server *kagentdevv1alpha1.MCPServer,
+	defaultBackend string,
+	defaults transportadapter.Defaults,
+) (*kagentdevv1alpha1.MCPServer, error) {
+	class, err := resolveServerClass(ctx, c, server)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := transportadapter.ApplyServerClass(server, class, defaultBackend, defaults)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errServerClass, err)
+	}
+	return merged, nil
+}
+
+// resolveServerClass returns the MCPServerClass of the MCPServer, or nil when it does not
+// name one and there is no default class.
+func resolveServerClass(
+	ctx context.Context,
+	c client.Reader,
+	server *kagentdevv1alpha1.MCPServer,
+) (*kagentdevv1alpha1.MCPServerClass, error) {
+	if server.Spec.ClassName != "" {
+		class := &kagentdevv1alpha1.MCPServerClass{}
+		if err := c.Get(ctx, client.ObjectKey{Name: server.Spec.ClassName}, class); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("%w: MCPServerClass %s not found", errServerClass, server.Spec.ClassName)
+			}
+			return nil, err
+		}
+		return class, nil
+	}
+
+	classes := &kagentdevv1alpha1.MCPServerClassList{}
+	if err := c.List(ctx, classes); err != nil {
+		return nil, err
+	}
+	var defaults []*kagentdevv1alpha1.MCPServerClass
+	for i := range classes.Items {
+		if transportadapter.IsDefaultServerClass(&classes.Items[i]) {
+			defaults = append(defaults, &classes.Items[i])
+		}
+	}
+	switch len(defaults) {
+	case 0:
+		return nil, nil
+	case 1:
+		return defaults[0], nil
+	default:
+		// Like IngressClasses, several default classes are ambiguous
+		names := make([]string, 0, len(defaults))
+		for _, class := range defaults {
+			names = append(names, class.Name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%w: more than one default MCPServerClass: %v", errServerClass, names)
+	}
+}
+
+// serversForClass maps an MCPServerClass to the MCPServers it applies to: the servers
+// naming it and, since the class may have become or stopped being the default, the
+// servers that do not name a class.
+func (r *MCPServerReconciler) serversForClass(ctx context.Context, obj client.Object) []reconcile.Request {
+	servers := &kagentdevv1alpha1.MCPServerList{}
+	if err := r.List(ctx, servers); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list MCPServers")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, server := range servers.Items {
+		if server.Spec.ClassName == obj.GetName() || server.Spec.ClassName == "" {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&server)})
+		}
+	}
+	return requests
+}
@@ -18,19 +18,31 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-
+	"hash/fnv"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/kagent-dev/kmcp/pkg/controller/config"
 	"github.com/kagent-dev/kmcp/pkg/controller/transportadapter"
 
+	"go.opentelemetry.io/otel"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
@@ -42,15 +54,48 @@ type MCPServerReconciler struct {
 	client.Client
 	Scheme  *runtime.Scheme
 	Plugins []transportadapter.TranslatorPlugin
+	// AdapterBackend is the transport adapter backend used for stdio MCPServers
+	// that do not select one. Defaults to agentgateway.
+	AdapterBackend string
+	// MonitorLabels are added to the ServiceMonitors and PodMonitors created for
+	// MCPServers exposing metrics, so that they are selected by Prometheus.
+	MonitorLabels map[string]string
+	// Config holds the controller configuration, such as the default security profile
+	// and the requeue intervals. The default configuration is used when nil.
+	Config *config.Watcher
+	// Recorder records the Events of MCPServers.
+	Recorder record.EventRecorder
+	// APIReader reads the outputs the cache does not hold, such as binding Secrets whose
+	// label was removed. Defaults to the client.
+	APIReader client.Reader
+	// ListTools lists the tools of MCPServers pinning their tools. Defaults to listing
+	// them through the Service of the MCPServer.
+	ListTools ToolLister
+	// Options configure the concurrency and the retries of the controller.
+	Options ReconcileOptions
+	// Scope selects the MCPServers reconciled by this controller. All MCPServers are
+	// reconciled by default.
+	Scope Scope
 }
 
 // +kubebuilder:rbac:groups=kagent.dev,resources=mcpservers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kagent.dev,resources=mcpservers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kagent.dev,resources=mcpservers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=kagent.dev,resources=mcpserverclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kagent.dev,resources=mcpservertemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kagent.dev,resources=mcpserverpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -61,7 +106,7 @@ type MCPServerReconciler struct {
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.20.0/pkg/reconcile
-func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	_ = log.FromContext(ctx)
 
 	// Fetch the MCPServer instance
@@ -70,56 +115,260 @@ func (r *MCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		// If the resource is not found, we can ignore the error since it will be requeued later
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	// MCPServers of other namespaces or shards are left to other controllers
+	if inScope, err := r.Scope.Includes(ctx, r.Client, mcpServer); err != nil || !inScope {
+		return ctrl.Result{}, err
+	}
+
+	ctx, span := startSpan(ctx, "MCPServer.Reconcile", mcpServer)
+	defer func() { endSpan(span, err) }()
+	// The configuration is read once, so that a reload does not change it mid-reconcile
+	cfg := r.Config.Current()
+	defaults := cfg.TranslationDefaults()
 
-	t := transportadapter.NewTransportAdapterTranslator(r.Scheme, r.Plugins)
-	outputs, err := t.TranslateTransportAdapterOutputs(ctx, mcpServer)
+	// The MCPServer is translated and validated once expanded from its template, with the
+	// defaults of its class merged in
+	expanded, err := expandServerTemplate(ctx, r.Client, mcpServer)
+	if err == nil {
+		expanded, err = validateExpandedServer(ctx, r.Client, mcpServer, expanded)
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to expand MCPServerTemplate")
+		r.reconcileStatus(ctx, cfg, mcpServer, err)
+		return ctrl.Result{}, err
+	}
+	merged, err := applyServerClass(ctx, r.Client, expanded, r.AdapterBackend, defaults)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to translate MCPServer outputs")
-		r.reconcileStatus(ctx, mcpServer, err)
+		log.FromContext(ctx).Error(err, "Failed to apply MCPServerClass")
+		r.reconcileStatus(ctx, cfg, mcpServer, err)
+		return ctrl.Result{}, err
+	}
+	mcpServer = merged
+
+	// MCPServers violating an enforced policy are not deployed, even when they were
+	// admitted before the policy was created
+	if _, err := checkServerPolicies(ctx, r.Client, mcpServer, defaults); err != nil {
+		log.FromContext(ctx).Error(err, "MCPServer violates MCPServerPolicy")
+		r.reconcileStatus(ctx, cfg, mcpServer, err)
 		return ctrl.Result{}, err
 	}
 
-	err = r.reconcileOutputs(ctx, outputs)
+	// Template instances were not validated at admission, they are not deployed until
+	// their expanded spec is valid
+	if mcpServer.Spec.TemplateRef != nil {
+		if err := r.validateServerConfig(cfg, mcpServer); err != nil {
+			log.FromContext(ctx).Error(err, "Invalid MCPServerTemplate instance")
+			r.reconcileStatus(ctx, cfg, mcpServer, err)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	if err := r.approveTools(ctx, mcpServer); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to approve the tools of MCPServer")
+		r.reconcileStatus(ctx, cfg, mcpServer, err)
+		return ctrl.Result{}, err
+	}
+
+	// The spec is translated and checked before it is stored as a revision, so that no
+	// revision is started that the workload cannot take
+	outputs, err := r.translateWorkload(ctx, cfg, mcpServer)
+	if err != nil {
+		return r.workloadFailed(ctx, cfg, mcpServer, err)
+	}
+
+	// The spec is stored as a revision; after a rollback an earlier revision is deployed
+	deployed, err := r.reconcileRevisions(ctx, mcpServer)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to reconcile MCPServer revisions")
+		r.reconcileStatus(ctx, cfg, mcpServer, err)
+		return ctrl.Result{}, err
+	}
+	if !equality.Semantic.DeepEqual(deployed.Spec, mcpServer.Spec) {
+		if outputs, err = r.translateWorkload(ctx, cfg, deployed); err != nil {
+			return r.workloadFailed(ctx, cfg, mcpServer, err)
+		}
+	}
+	annotateRevision(outputs, mcpServer.Status.CurrentRevision)
+	// A workload whose tools changed under the block policy serves nothing until approved
+	if toolsBlocked(mcpServer) {
+		suspendWorkload(outputs)
+	}
+
+	err = r.reconcileOutputs(ctx, mcpServer, outputs)
 	if err != nil {
 		log.FromContext(ctx).Error(err, "Failed to reconcile outputs")
-		r.reconcileStatus(ctx, mcpServer, err)
+		r.reconcileStatus(ctx, cfg, mcpServer, err)
+		// A conflicting object is not watched, so its removal is only noticed by polling
+		if errors.Is(err, errOutputConflict) {
+			return ctrl.Result{RequeueAfter: cfg.Requeue.NotReady.Duration}, nil
+		}
 		return ctrl.Result{}, err
 	}
 
-	r.reconcileStatus(ctx, mcpServer, nil)
+	// Publish the address of the MCPServer and its binding Secret
+	endpoints := transportadapter.ServerEndpoints(deployed)
+	mcpServer.Status.URL = transportadapter.ServerURL(endpoints)
+	mcpServer.Status.Endpoints = &endpoints
+	mcpServer.Status.Binding = &corev1.LocalObjectReference{Name: transportadapter.BindingSecretName(mcpServer)}
+
+	// Remove the workload of the other kind when the workload kind was switched
+	if err := r.deleteStaleWorkload(ctx, deployed); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to delete stale workload")
+		r.reconcileStatus(ctx, cfg, mcpServer, err)
+		return ctrl.Result{}, err
+	}
+
+	// Remove the monitors that are no longer requested
+	if err := r.deleteStaleMonitors(ctx, deployed); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to delete stale monitors")
+		r.reconcileStatus(ctx, cfg, mcpServer, err)
+		return ctrl.Result{}, err
+	}
+
+	// The next reconcile rolls out or holds back the revision whose tools were verified
+	requeue, err := r.verifyTools(ctx, cfg, mcpServer, deployed)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to verify the tools of MCPServer")
+		r.reconcileStatus(ctx, cfg, mcpServer, err)
+		return ctrl.Result{}, err
+	}
+	if requeue {
+		r.reconcileStatus(ctx, cfg, mcpServer, nil)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// A revision is only good once its tools match the pinned tools
+	var rollbackIn time.Duration
+	if toolsPending(mcpServer) {
+		rollbackIn = cfg.Requeue.ToolsCheck.Duration
+	} else {
+		rollbackIn = r.recordReadyRevision(ctx, mcpServer, deployed)
+	}
+	r.reconcileStatus(ctx, cfg, mcpServer, nil)
 
-	// If the deployment is not ready, requeue after a short interval to check again
-	deployment := &appsv1.Deployment{}
-	deploymentName := mcpServer.Name
-	if err := r.Get(ctx, client.ObjectKey{Name: deploymentName, Namespace: mcpServer.Namespace}, deployment); err == nil {
-		if deployment.Status.AvailableReplicas == 0 || deployment.Status.AvailableReplicas < deployment.Status.Replicas {
-			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	// Readiness changes of the workload and its pods trigger a reconcile, so a workload
+	// that is not ready is only checked again after a long interval as a safety net
+	requeueAfter := rollbackIn
+	if recheckIn := toolsRecheckIn(cfg, mcpServer); recheckIn > 0 && (requeueAfter == 0 || requeueAfter > recheckIn) {
+		requeueAfter = recheckIn
+	}
+	if status, err := r.getWorkloadStatus(ctx, deployed); err == nil {
+		if status.availableReplicas == 0 || status.availableReplicas < status.replicas {
+			if notReady := cfg.Requeue.NotReady.Duration; requeueAfter == 0 || requeueAfter > notReady {
+				requeueAfter = notReady
+			}
 		}
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MCPServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&kagentdevv1alpha1.MCPServer{}, builder.WithPredicates(
 			predicate.Or(
 				predicate.GenerationChangedPredicate{},
 				predicate.LabelChangedPredicate{},
+				// The rollback annotation requests a rollback
+				predicate.AnnotationChangedPredicate{},
 			),
 		)).
 		Owns(&appsv1.Deployment{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Owns(&appsv1.StatefulSet{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
 		Owns(&corev1.Service{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
 		Owns(&corev1.ConfigMap{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
-		Named("mcpserver").
-		Complete(r)
+		Owns(&corev1.Secret{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		// Pods becoming ready or not update the Ready condition without polling
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(serverForPod),
+			builder.WithPredicates(podReadinessChangedPredicate())).
+		Watches(&kagentdevv1alpha1.MCPServerClass{}, handler.EnqueueRequestsFromMapFunc(r.serversForClass)).
+		Watches(&kagentdevv1alpha1.MCPServerTemplate{}, handler.EnqueueRequestsFromMapFunc(r.serversForTemplate)).
+		Watches(&kagentdevv1alpha1.MCPServerPolicy{}, handler.EnqueueRequestsFromMapFunc(r.serversForPolicy)).
+		WithOptions(r.Options.controllerOptions())
+	if r.Scope.selectsNamespaces() {
+		// Namespaces whose labels changed may come in or out of scope
+		b = b.Watches(&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(requestsInNamespace(r.Client, func() client.ObjectList {
+				return &kagentdevv1alpha1.MCPServerList{}
+			})),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}))
+	}
+	if r.Config != nil {
+		b = b.WatchesRawSource(configReloads(r.Config, r.Client, func() client.ObjectList {
+			return &kagentdevv1alpha1.MCPServerList{}
+		}))
+	}
+	return b.Named("mcpserver").Complete(r)
+}
+
+func (r *MCPServerReconciler) translateOutputs(
+	ctx context.Context,
+	cfg *config.ControllerConfig,
+	server *kagentdevv1alpha1.MCPServer,
+) (outputs []client.Object, err error) {
+	ctx, span := startSpan(ctx, "MCPServer.Translate", server)
+	defer func() { endSpan(span, err) }()
+
+	t := transportadapter.NewTransportAdapterTranslator(
+		r.Scheme,
+		r.Plugins,
+		transportadapter.WithDefaults(cfg.TranslationDefaults()),
+		transportadapter.WithDefaultAdapterBackend(r.AdapterBackend),
+		transportadapter.WithMonitoring(r.MonitorLabels, r.availableMonitorKinds()...),
+		transportadapter.WithSecurityProfile(cfg.SecurityProfile.Default, cfg.SecurityProfile.SandboxRuntimeClassName),
+	)
+	return t.TranslateTransportAdapterOutputs(ctx, server)
+}
+
+// translateWorkload translates the MCPServer, and checks that the outputs do not change
+// the immutable fields of the existing workload.
+func (r *MCPServerReconciler) translateWorkload(
+	ctx context.Context,
+	cfg *config.ControllerConfig,
+	server *kagentdevv1alpha1.MCPServer,
+) ([]client.Object, error) {
+	outputs, err := r.translateOutputs(ctx, cfg, server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate MCPServer outputs: %w", err)
+	}
+	if err := r.checkImmutableFields(ctx, outputs); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// workloadFailed reports the error of translateWorkload. Changes the workload cannot take
+// are reported rather than retried.
+func (r *MCPServerReconciler) workloadFailed(
+	ctx context.Context,
+	cfg *config.ControllerConfig,
+	server *kagentdevv1alpha1.MCPServer,
+	err error,
+) (ctrl.Result, error) {
+	log.FromContext(ctx).Error(err, "Failed to translate the workload of MCPServer")
+	r.reconcileStatus(ctx, cfg, server, err)
+	if errors.Is(err, errImmutableField) {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, err
 }
 
-func (r *MCPServerReconciler) reconcileOutputs(ctx context.Context, outputs []client.Object) error {
+func (r *MCPServerReconciler) reconcileOutputs(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+	outputs []client.Object,
+) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "MCPServer.ReconcileOutputs")
+	defer func() { endSpan(span, err) }()
+
 	// upsert the outputs to the cluster
 	for _, output := range outputs {
-		if err := upsertOutput(ctx, r.Client, output); err != nil {
+		err := upsertOutput(ctx, r.Client, output)
+		if apierrors.IsAlreadyExists(err) {
+			err = r.updateUncachedOutput(ctx, server, output)
+		}
+		if err != nil {
 			return err
 		}
 	}
@@ -127,17 +376,63 @@ func (r *MCPServerReconciler) reconcileOutputs(ctx context.Context, outputs []cl
 	return nil
 }
 
+// errOutputConflict marks the outputs of an MCPServer whose name is taken by an object it
+// does not own, which are reported as configuration errors on the Accepted condition.
+var errOutputConflict = errors.New("output conflict")
+
+// updateUncachedOutput updates an output that exists although the cache does not hold
+// it, such as a binding Secret whose label was removed. An object of the same name that
+// the MCPServer does not own is reported as a conflict rather than overwritten.
+func (r *MCPServerReconciler) updateUncachedOutput(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+	output client.Object,
+) error {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	existing := output.DeepCopyObject().(client.Object)
+	if err := reader.Get(ctx, client.ObjectKeyFromObject(output), existing); err != nil {
+		return err
+	}
+	if !isOwnedBy(existing, server) {
+		return fmt.Errorf("%w: %s %s already exists and is not owned by MCPServer %s, delete or rename it",
+			errOutputConflict, output.GetObjectKind().GroupVersionKind().Kind, output.GetName(), server.Name)
+	}
+	output.SetResourceVersion(existing.GetResourceVersion())
+	return r.Update(ctx, output)
+}
+
 func (r *MCPServerReconciler) reconcileStatus(
 	ctx context.Context,
+	cfg *config.ControllerConfig,
 	server *kagentdevv1alpha1.MCPServer,
 	reconcileErr error,
 ) {
+	ctx, span := startSpan(ctx, "MCPServer.ReconcileStatus", server)
+	defer span.End()
+
 	// Update ObservedGeneration
 	server.Status.ObservedGeneration = server.Generation
 
 	// Set Accepted condition based on validation
-	if err := r.validateMCPServer(server); err != nil {
-		setAcceptedCondition(server, false, kagentdevv1alpha1.MCPServerReasonInvalidConfig, err.Error())
+	// The spec of a template instance is only meaningful once expanded, so the errors of
+	// its template take precedence over the validation of the spec
+	var policyWarnings []string
+	validationErr := reconcileErr
+	if !errors.Is(reconcileErr, errServerTemplate) {
+		policyWarnings, validationErr = r.validateMCPServer(ctx, cfg, server)
+		if validationErr == nil && (isReferenceError(reconcileErr) || isConfigurationError(reconcileErr)) {
+			validationErr = reconcileErr
+		}
+	}
+	if err := validationErr; err != nil {
+		reason := kagentdevv1alpha1.MCPServerReasonInvalidConfig
+		if errors.Is(err, errServerPolicy) {
+			reason = kagentdevv1alpha1.MCPServerReasonPolicyViolation
+		}
+		setAcceptedCondition(server, false, reason, err.Error())
 		// If validation fails, set other conditions as unknown/false
 		setResolvedRefsCondition(
 			server,
@@ -158,11 +453,16 @@ func (r *MCPServerReconciler) reconcileStatus(
 			"Configuration validation failed",
 		)
 	} else {
+		message := "MCPServer configuration is valid"
+		if len(policyWarnings) > 0 {
+			// Violations of audited policies do not prevent the MCPServer from being deployed
+			message += "; audit: " + strings.Join(policyWarnings, "; ")
+		}
 		setAcceptedCondition(
 			server,
 			true,
 			kagentdevv1alpha1.MCPServerReasonAccepted,
-			"MCPServer configuration is valid",
+			message,
 		)
 
 		// Set ResolvedRefs condition (for now, assume image exists - could be enhanced later)
@@ -186,6 +486,15 @@ func (r *MCPServerReconciler) reconcileStatus(
 				kagentdevv1alpha1.MCPServerReasonPodsNotReady,
 				"Resources failed to be created",
 			)
+		} else if server.Status.RolledBackRevision != "" {
+			setProgrammedCondition(server,
+				true,
+				kagentdevv1alpha1.MCPServerReasonRolledBack,
+				fmt.Sprintf("Rolled back from revision %s to revision %s",
+					server.Status.RolledBackRevision, server.Status.CurrentRevision),
+			)
+
+			r.checkReadyCondition(ctx, server)
 		} else {
 			setProgrammedCondition(server,
 				true,
@@ -198,14 +507,39 @@ func (r *MCPServerReconciler) reconcileStatus(
 		}
 	}
 
-	// Update the status
+	setToolsChangedCondition(server)
+
+	// Update the status, unless it is unchanged
+	current := &kagentdevv1alpha1.MCPServer{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(server), current); err == nil &&
+		equality.Semantic.DeepEqual(current.Status, server.Status) {
+		return
+	}
 	if err := r.Status().Update(ctx, server); err != nil {
 		log.FromContext(ctx).Error(err, "Failed to update MCPServer status")
 	}
 }
 
-// validateMCPServer validates the MCPServer configuration
-func (r *MCPServerReconciler) validateMCPServer(server *kagentdevv1alpha1.MCPServer) error {
+// validateMCPServer validates the MCPServer configuration, and checks it against the
+// MCPServerPolicies. The violations of audited policies are returned as warnings.
+func (r *MCPServerReconciler) validateMCPServer(
+	ctx context.Context,
+	cfg *config.ControllerConfig,
+	server *kagentdevv1alpha1.MCPServer,
+) ([]string, error) {
+	if err := r.validateServerConfig(cfg, server); err != nil {
+		return nil, err
+	}
+	return checkServerPolicies(ctx, r.Client, server, cfg.TranslationDefaults())
+}
+
+// validateServerConfig validates the MCPServer configuration
+func (r *MCPServerReconciler) validateServerConfig(
+	cfg *config.ControllerConfig,
+	server *kagentdevv1alpha1.MCPServer,
+) error {
+	defaults := cfg.TranslationDefaults()
+
 	// Check if transport type is supported
 	if server.Spec.TransportType != kagentdevv1alpha1.TransportTypeStdio &&
 		server.Spec.TransportType != kagentdevv1alpha1.TransportTypeHTTP {
@@ -213,48 +547,319 @@ func (r *MCPServerReconciler) validateMCPServer(server *kagentdevv1alpha1.MCPSer
 	}
 
 	// Check if required fields are present
-	// Allow empty image if command is npx or uvx (default images will be injected)
+	// Allow empty image if the command is a runner (its default image will be injected)
 	if server.Spec.Deployment.Image == "" {
-		if server.Spec.Deployment.Cmd != "npx" && server.Spec.Deployment.Cmd != "uvx" {
-			return fmt.Errorf("deployment.image is required when command is not 'npx' or 'uvx'")
+		if _, ok := defaults.LookupRunner(server.Spec.Deployment.Cmd); !ok {
+			return fmt.Errorf("deployment.image is required when command is not one of the runners %v",
+				defaults.RunnerNames())
+		}
+	}
+
+	// Sidecar adapter mode runs deployment.cmd behind the exec bridge
+	if server.Spec.TransportType == kagentdevv1alpha1.TransportTypeStdio &&
+		server.Spec.StdioTransport != nil &&
+		server.Spec.StdioTransport.AdapterMode == kagentdevv1alpha1.StdioAdapterModeSidecar &&
+		server.Spec.Deployment.Cmd == "" {
+		return fmt.Errorf("deployment.cmd is required when stdioTransport.adapterMode is 'Sidecar'")
+	}
+
+	// Adapter backends that cannot be copied into the MCP server container need Sidecar mode
+	if server.Spec.TransportType == kagentdevv1alpha1.TransportTypeStdio {
+		backend, err := transportadapter.LookupAdapterBackend(adapterBackendName(server, r.AdapterBackend))
+		if err != nil {
+			return err
+		}
+		if !backend.SupportsCopyBinary() &&
+			(server.Spec.StdioTransport == nil ||
+				server.Spec.StdioTransport.AdapterMode != kagentdevv1alpha1.StdioAdapterModeSidecar) {
+			return fmt.Errorf("adapter backend %s requires stdioTransport.adapterMode to be 'Sidecar'", backend.Name())
+		}
+	}
+
+	if err := validateMetrics(server, r.AdapterBackend); err != nil {
+		return err
+	}
+	if err := transportadapter.CheckPackageCache(server, defaults); err != nil {
+		return err
+	}
+	if err := transportadapter.CheckService(server); err != nil {
+		return err
+	}
+	securityProfile := transportadapter.SecurityProfileOf(server, cfg.SecurityProfile.Default)
+	if err := transportadapter.CheckSecurityProfile(server, securityProfile); err != nil {
+		return err
+	}
+
+	// A variable defined twice would silently shadow the other definition
+	envNames := make(map[string]bool, len(server.Spec.Deployment.EnvVars))
+	for _, envVar := range server.Spec.Deployment.EnvVars {
+		if _, ok := server.Spec.Deployment.Env[envVar.Name]; ok {
+			return fmt.Errorf("environment variable %s is defined in both deployment.env and deployment.envVars", envVar.Name)
+		}
+		if envNames[envVar.Name] {
+			return fmt.Errorf("environment variable %s is defined more than once in deployment.envVars", envVar.Name)
 		}
+		envNames[envVar.Name] = true
+	}
+
+	// Volume claim templates are only honored by StatefulSets
+	if len(server.Spec.Deployment.VolumeClaimTemplates) > 0 &&
+		server.Spec.Deployment.WorkloadKind != kagentdevv1alpha1.WorkloadKindStatefulSet {
+		return fmt.Errorf("deployment.volumeClaimTemplates requires deployment.workloadKind to be 'StatefulSet'")
 	}
 
 	// Additional validation could be added here
 	return nil
 }
 
-// checkReadyCondition checks if the MCPServer is ready by examining the deployment status
+// isReferenceError reports whether the error is caused by the MCPServerClass or the
+// MCPServerTemplate referenced by the MCPServer, or by an MCPServerPolicy.
+func isReferenceError(err error) bool {
+	return errors.Is(err, errServerClass) || errors.Is(err, errServerTemplate) || errors.Is(err, errServerPolicy)
+}
+
+// isConfigurationError reports whether the error is caused by a change of the MCPServer
+// that its existing workload cannot take, or by an output whose name is taken.
+func isConfigurationError(err error) bool {
+	return errors.Is(err, errImmutableField) || errors.Is(err, errOutputConflict)
+}
+
+// validateMetrics checks that metrics are served by an adapter that supports them on a
+// port that does not collide with the MCP server port.
+func validateMetrics(server *kagentdevv1alpha1.MCPServer, defaultBackend string) error {
+	if server.Spec.Observability == nil || server.Spec.Observability.Metrics == nil {
+		return nil
+	}
+	metrics := server.Spec.Observability.Metrics
+
+	if server.Spec.TransportType != kagentdevv1alpha1.TransportTypeStdio {
+		return fmt.Errorf("observability.metrics requires the stdio transport")
+	}
+	if backendName := adapterBackendName(server, defaultBackend); backendName != transportadapter.AgentgatewayBackendName {
+		return fmt.Errorf("observability.metrics is not supported by adapter backend %s", backendName)
+	}
+	if metrics.Port != 0 && metrics.Port == server.Spec.Deployment.Port {
+		return fmt.Errorf("observability.metrics.port must differ from deployment.port")
+	}
+	return nil
+}
+
+// adapterBackendName returns the adapter backend selected by a stdio MCPServer, falling
+// back to the controller default and then to agentgateway.
+func adapterBackendName(server *kagentdevv1alpha1.MCPServer, defaultBackend string) string {
+	if server.Spec.StdioTransport != nil && server.Spec.StdioTransport.AdapterBackend != "" {
+		return server.Spec.StdioTransport.AdapterBackend
+	}
+	if defaultBackend != "" {
+		return defaultBackend
+	}
+	return transportadapter.AgentgatewayBackendName
+}
+
+// workloadStatus summarizes the replica status of the workload backing an MCPServer.
+type workloadStatus struct {
+	replicas          int32
+	availableReplicas int32
+	// rolledOut is whether the workload runs its latest pod template on all of its
+	// replicas, and they are all available.
+	rolledOut bool
+	// revision is the revision of the MCPServer the workload was last updated to.
+	revision string
+}
+
+// getWorkloadStatus returns the replica status of the Deployment or StatefulSet
+// backing the MCPServer, depending on its workload kind.
+func (r *MCPServerReconciler) getWorkloadStatus(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+) (*workloadStatus, error) {
+	key := client.ObjectKey{Name: server.Name, Namespace: server.Namespace}
+	switch server.Spec.Deployment.WorkloadKind {
+	case kagentdevv1alpha1.WorkloadKindStatefulSet:
+		statefulSet := &appsv1.StatefulSet{}
+		if err := r.Get(ctx, key, statefulSet); err != nil {
+			return nil, err
+		}
+		desired := replicasOf(statefulSet.Spec.Replicas)
+		return &workloadStatus{
+			replicas:          statefulSet.Status.Replicas,
+			availableReplicas: statefulSet.Status.AvailableReplicas,
+			rolledOut: statefulSet.Status.ObservedGeneration >= statefulSet.Generation &&
+				statefulSet.Status.UpdateRevision == statefulSet.Status.CurrentRevision &&
+				statefulSet.Status.UpdatedReplicas == desired &&
+				statefulSet.Status.AvailableReplicas == desired,
+			revision: statefulSet.Annotations[revisionAnnotation],
+		}, nil
+	default:
+		deployment := &appsv1.Deployment{}
+		if err := r.Get(ctx, key, deployment); err != nil {
+			return nil, err
+		}
+		desired := replicasOf(deployment.Spec.Replicas)
+		return &workloadStatus{
+			replicas:          deployment.Status.Replicas,
+			availableReplicas: deployment.Status.AvailableReplicas,
+			rolledOut: deployment.Status.ObservedGeneration >= deployment.Generation &&
+				deployment.Status.Replicas == desired &&
+				deployment.Status.UpdatedReplicas == desired &&
+				deployment.Status.AvailableReplicas == desired,
+			revision: deployment.Annotations[revisionAnnotation],
+		}, nil
+	}
+}
+
+// deleteStaleWorkload deletes the workload of the kind that is no longer selected
+// by the MCPServer, so that switching between Deployment and StatefulSet does not
+// leave two sets of pods running behind the same Service.
+func (r *MCPServerReconciler) deleteStaleWorkload(ctx context.Context, server *kagentdevv1alpha1.MCPServer) error {
+	var stale client.Object = &appsv1.StatefulSet{}
+	if server.Spec.Deployment.WorkloadKind == kagentdevv1alpha1.WorkloadKindStatefulSet {
+		stale = &appsv1.Deployment{}
+	}
+
+	if err := r.Get(ctx, client.ObjectKey{Name: server.Name, Namespace: server.Namespace}, stale); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !isOwnedBy(stale, server) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, stale))
+}
+
+// errImmutableField marks the changes of an MCPServer that its existing workload cannot
+// take, which are reported as configuration errors on the Accepted condition.
+var errImmutableField = errors.New("immutable field changed")
+
+// checkImmutableFields checks that the outputs do not change the fields of the existing
+// workload that cannot be updated, so that the update does not fail on every reconcile.
+// The volume claim templates of a StatefulSet cannot be changed.
+func (r *MCPServerReconciler) checkImmutableFields(ctx context.Context, outputs []client.Object) error {
+	for _, output := range outputs {
+		desired, ok := output.(*appsv1.StatefulSet)
+		if !ok {
+			continue
+		}
+		existing := &appsv1.StatefulSet{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		if !volumeClaimTemplatesEqual(desired.Spec.VolumeClaimTemplates, existing.Spec.VolumeClaimTemplates) {
+			return fmt.Errorf("%w: deployment.volumeClaimTemplates cannot be changed once StatefulSet %s is created, "+
+				"revert the change or delete the StatefulSet to recreate it", errImmutableField, existing.Name)
+		}
+	}
+	return nil
+}
+
+// volumeClaimTemplatesEqual reports whether the existing volume claim templates match the
+// desired ones. The fields defaulted by the API server are ignored.
+func volumeClaimTemplatesEqual(desired, existing []corev1.PersistentVolumeClaim) bool {
+	if len(desired) != len(existing) {
+		return false
+	}
+	for i := range desired {
+		if !equality.Semantic.DeepDerivative(desired[i], existing[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isOwnedBy reports whether the object has an owner reference to the MCPServer.
+func isOwnedBy(obj client.Object, server *kagentdevv1alpha1.MCPServer) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == server.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// availableMonitorKinds returns the Prometheus Operator monitor kinds whose CRDs are
+// installed in the cluster. The REST mapper discovers CRDs installed after the
+// controller started.
+func (r *MCPServerReconciler) availableMonitorKinds() []kagentdevv1alpha1.MetricsMonitorKind {
+	var kinds []kagentdevv1alpha1.MetricsMonitorKind
+	for _, kind := range transportadapter.MonitorKinds() {
+		gvk := transportadapter.MonitorGVK(kind)
+		if _, err := r.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// deleteStaleMonitors deletes the monitors owned by the MCPServer that it no longer
+// requests, after metrics were disabled or the monitor kind was switched.
+func (r *MCPServerReconciler) deleteStaleMonitors(ctx context.Context, server *kagentdevv1alpha1.MCPServer) error {
+	var wanted kagentdevv1alpha1.MetricsMonitorKind
+	if server.Spec.Observability != nil && server.Spec.Observability.Metrics != nil {
+		wanted = server.Spec.Observability.Metrics.Monitor
+		if wanted == "" {
+			wanted = kagentdevv1alpha1.MetricsMonitorKindServiceMonitor
+		}
+	}
+
+	for _, kind := range r.availableMonitorKinds() {
+		if kind == wanted {
+			continue
+		}
+		stale := &unstructured.Unstructured{}
+		stale.SetGroupVersionKind(transportadapter.MonitorGVK(kind))
+		if err := r.Get(ctx, client.ObjectKey{Name: server.Name, Namespace: server.Namespace}, stale); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		if !isOwnedBy(stale, server) {
+			continue
+		}
+		if err := r.Delete(ctx, stale); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkReadyCondition checks if the MCPServer is ready by examining the workload status
 func (r *MCPServerReconciler) checkReadyCondition(ctx context.Context, server *kagentdevv1alpha1.MCPServer) {
-	// Get the deployment
-	deployment := &appsv1.Deployment{}
-	deploymentName := server.Name
-	if err := r.Get(ctx, client.ObjectKey{Name: deploymentName, Namespace: server.Namespace}, deployment); err != nil {
+	kind := server.Spec.Deployment.WorkloadKind
+	if kind == "" {
+		kind = kagentdevv1alpha1.WorkloadKindDeployment
+	}
+
+	// Get the workload
+	status, err := r.getWorkloadStatus(ctx, server)
+	if err != nil {
 		if client.IgnoreNotFound(err) == nil {
-			setReadyCondition(server, false, kagentdevv1alpha1.MCPServerReasonPodsNotReady, "Deployment not found")
+			setReadyCondition(server, false, kagentdevv1alpha1.MCPServerReasonPodsNotReady, fmt.Sprintf("%s not found", kind))
 		} else {
 			setReadyCondition(
 				server,
 				false,
 				kagentdevv1alpha1.MCPServerReasonPodsNotReady,
-				fmt.Sprintf("Error getting deployment: %s", err.Error()),
+				fmt.Sprintf("Error getting %s: %s", strings.ToLower(string(kind)), err.Error()),
 			)
 		}
 		return
 	}
 
-	// Check if deployment is available
-	// A deployment is considered ready when it has the desired number of available replicas
-	if deployment.Status.AvailableReplicas > 0 && deployment.Status.AvailableReplicas == deployment.Status.Replicas {
+	// Check if the workload is available
+	// A workload is considered ready when it has the desired number of available replicas
+	if status.availableReplicas > 0 && status.availableReplicas == status.replicas {
 		setReadyCondition(
 			server,
 			true,
 			kagentdevv1alpha1.MCPServerReasonAvailable,
-			"Deployment is ready and all pods are running",
+			fmt.Sprintf("%s is ready and all pods are running", kind),
 		)
 	} else {
-		message := fmt.Sprintf("Deployment not ready: %d/%d replicas available",
-			deployment.Status.AvailableReplicas, deployment.Status.Replicas)
+		message := fmt.Sprintf("%s not ready: %d/%d replicas available",
+			kind, status.availableReplicas, status.replicas)
 		setReadyCondition(server, false, kagentdevv1alpha1.MCPServerReasonNotAvailable, message)
 	}
 }
@@ -352,7 +957,71 @@ func setReadyCondition(
 	setCondition(server, kagentdevv1alpha1.MCPServerConditionReady, status, reason, message)
 }
 
+// specHashAnnotation records on the outputs of the controllers the hash of the object
+// they were last written with, so that unchanged outputs are not written again unless
+// they drifted from it.
+const specHashAnnotation = "kmcp.kagent.dev/spec-hash"
+
+// setSpecHash records the hash of the output on it.
+func setSpecHash(output client.Object) error {
+	annotations := output.GetAnnotations()
+	delete(annotations, specHashAnnotation)
+	data, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", output.GetName(), err)
+	}
+	hasher := fnv.New64a()
+	hasher.Write(data) //nolint:errcheck // hash writes never fail
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[specHashAnnotation] = rand.SafeEncodeString(strconv.FormatUint(hasher.Sum64(), 10))
+	output.SetAnnotations(annotations)
+	return nil
+}
+
+// headlessChanged reports whether the desired Service switches to or from headless.
+func headlessChanged(existing, output client.Object) bool {
+	existingService, ok := existing.(*corev1.Service)
+	if !ok {
+		return false
+	}
+	service, ok := output.(*corev1.Service)
+	if !ok {
+		return false
+	}
+	return (existingService.Spec.ClusterIP == corev1.ClusterIPNone) != (service.Spec.ClusterIP == corev1.ClusterIPNone)
+}
+
+// outputDrifted reports whether the live output lost any of the desired labels, annotations
+// or fields, for instance because it was edited by hand. Fields only set on the live output,
+// such as the defaults of the API server and the status, are ignored.
+func outputDrifted(existing, output client.Object) (bool, error) {
+	if !equality.Semantic.DeepDerivative(output.GetLabels(), existing.GetLabels()) ||
+		!equality.Semantic.DeepDerivative(output.GetAnnotations(), existing.GetAnnotations()) {
+		return true, nil
+	}
+	desired, err := runtime.DefaultUnstructuredConverter.ToUnstructured(output)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert %s: %w", output.GetName(), err)
+	}
+	live, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert %s: %w", existing.GetName(), err)
+	}
+	for _, content := range []map[string]interface{}{desired, live} {
+		delete(content, "apiVersion")
+		delete(content, "kind")
+		delete(content, "metadata")
+		delete(content, "status")
+	}
+	return !equality.Semantic.DeepDerivative(desired, live), nil
+}
+
 func upsertOutput(ctx context.Context, kube client.Client, output client.Object) error {
+	if err := setSpecHash(output); err != nil {
+		return err
+	}
 	existing := output.DeepCopyObject().(client.Object)
 	if err := kube.Get(ctx, client.ObjectKeyFromObject(existing), existing); err != nil {
 		if client.IgnoreNotFound(err) != nil {
@@ -362,8 +1031,27 @@ func upsertOutput(ctx context.Context, kube client.Client, output client.Object)
 		if err := kube.Create(ctx, output); err != nil {
 			return err
 		}
+	} else if headlessChanged(existing, output) {
+		// The cluster IP of a Service is immutable, it is recreated to switch to or from headless
+		if err := kube.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err := kube.Create(ctx, output); err != nil {
+			return err
+		}
 	} else {
-		// If found, update it
+		// If found and changed, or edited since it was written, update it
+		changed := existing.GetAnnotations()[specHashAnnotation] != output.GetAnnotations()[specHashAnnotation]
+		if !changed {
+			drifted, err := outputDrifted(existing, output)
+			if err != nil {
+				return err
+			}
+			changed = drifted
+		}
+		if !changed {
+			return nil
+		}
 		output.SetResourceVersion(existing.GetResourceVersion())
 		if err := kube.Update(ctx, output); err != nil {
 			return err
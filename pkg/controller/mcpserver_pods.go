@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// PodCacheOptions returns the cache options of pods. Only the pods of MCPServers are
+// cached, and only the fields needed to follow their readiness.
+func PodCacheOptions() cache.ByObject {
+	requirement, err := labels.NewRequirement(kagentdevv1alpha1.MCPServerNameLabel, selection.Exists, nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid pod cache selector: %v", err))
+	}
+	return cache.ByObject{
+		Label: labels.NewSelector().Add(*requirement),
+		Transform: func(obj any) (any, error) {
+			pod, ok := obj.(*corev1.Pod)
+			if !ok {
+				return obj, nil
+			}
+			objectMeta := pod.ObjectMeta
+			objectMeta.ManagedFields = nil
+			return &corev1.Pod{
+				TypeMeta:   pod.TypeMeta,
+				ObjectMeta: objectMeta,
+				Status: corev1.PodStatus{
+					Phase:      pod.Status.Phase,
+					Conditions: pod.Status.Conditions,
+				},
+			}, nil
+		},
+	}
+}
+
+// serverForPod enqueues the MCPServer named by the name label of a pod.
+func serverForPod(_ context.Context, obj client.Object) []reconcile.Request {
+	name := obj.GetLabels()[kagentdevv1alpha1.MCPServerNameLabel]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: name, Namespace: obj.GetNamespace()}}}
+}
+
+// podReadinessChangedPredicate passes the pods whose readiness changed, and the deleted
+// pods. Creations are followed by the events of the workload.
+func podReadinessChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			return podReady(oldPod) != podReady(newPod)
+		},
+		DeleteFunc: func(event.DeleteEvent) bool { return true },
+	}
+}
+
+// podReady reports whether the pod has the Ready condition.
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/kagent-dev/kmcp/pkg/controller/transportadapter"
+)
+
+// errServerPolicy marks the violations of enforced MCPServerPolicies, which are reported
+// as policy violations on the Accepted condition.
+var errServerPolicy = errors.New("MCPServer rejected by policy")
+
+// checkServerPolicies checks the MCPServer against the MCPServerPolicies selecting its
+// namespace. The violations of enforced policies are returned as an error; those of
+// audited policies are returned as warnings.
+func checkServerPolicies(
+	ctx context.Context,
+	c client.Reader,
+	server *kagentdevv1alpha1.MCPServer,
+	defaults transportadapter.Defaults,
+) ([]string, error) {
+	policies := &kagentdevv1alpha1.MCPServerPolicyList{}
+	if err := c.List(ctx, policies); err != nil {
+		return nil, err
+	}
+	if len(policies.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: server.Namespace}, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get the namespace of MCPServer %s: %w", server.Name, err)
+	}
+
+	var warnings, violations []string
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		selected, err := policySelectsNamespace(policy, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !selected {
+			continue
+		}
+		if err := transportadapter.CheckServerPolicy(server, policy, defaults); err != nil {
+			if policy.Spec.Mode == kagentdevv1alpha1.PolicyModeAudit {
+				warnings = append(warnings, err.Error())
+			} else {
+				violations = append(violations, err.Error())
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return warnings, fmt.Errorf("%w: %s", errServerPolicy, strings.Join(violations, "; "))
+	}
+	return warnings, nil
+}
+
+func policySelectsNamespace(policy *kagentdevv1alpha1.MCPServerPolicy, namespace *corev1.Namespace) (bool, error) {
+	if policy.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid namespaceSelector of MCPServerPolicy %s: %w", policy.Name, err)
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// serversForPolicy maps an MCPServerPolicy to all MCPServers, since the namespaces the
+// policy selected before the change are not known.
+func (r *MCPServerReconciler) serversForPolicy(ctx context.Context, _ client.Object) []reconcile.Request {
+	servers := &kagentdevv1alpha1.MCPServerList{}
+	if err := r.List(ctx, servers); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list MCPServers")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(servers.Items))
+	for _, server := range servers.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&server)})
+	}
+	return requests
+}
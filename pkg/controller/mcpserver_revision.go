@@ -0,0 +1,361 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+const (
+	defaultRevisionHistoryLimit = 10
+	defaultProgressDeadline     = 10 * time.Minute
+
+	// revisionAnnotation records on the workload the revision it was last updated to.
+	revisionAnnotation = "mcpserver.kagent.dev/revision"
+)
+
+// reconcileRevisions stores the spec of the MCPServer as a ControllerRevision, and
+// returns a copy of the MCPServer with the spec of the revision to deploy. That is the
+// revision of the spec, unless the MCPServer was rolled back from it, manually through
+// the rollback annotation or because it did not become ready within the progress
+// deadline, or its tools are still to be verified on a canary. The revisions are
+// recorded in the status of the MCPServer.
+func (r *MCPServerReconciler) reconcileRevisions(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+) (*kagentdevv1alpha1.MCPServer, error) {
+	revisions, err := r.listRevisions(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	updateRevision, err := r.ensureRevision(ctx, server, revisions)
+	if err != nil {
+		return nil, err
+	}
+	if findRevision(revisions, updateRevision.Name) == nil {
+		revisions = append(revisions, updateRevision)
+	}
+
+	status := &server.Status
+	status.ToolsCanaryRevision = ""
+	target := updateRevision
+	rollbackTo, rollbackRequested := server.Annotations[kagentdevv1alpha1.MCPServerRollbackAnnotation]
+	switch {
+	case rollbackRequested:
+		target, err = rollbackTarget(rollbackTo, status, revisions)
+		if err != nil {
+			return nil, err
+		}
+		status.RolledBackRevision = ""
+		if target.Name != updateRevision.Name {
+			status.RolledBackRevision = updateRevision.Name
+		}
+		log.FromContext(ctx).Info("Rolling back MCPServer", "revision", target.Revision)
+		if err := r.removeAnnotation(ctx, server, kagentdevv1alpha1.MCPServerRollbackAnnotation); err != nil {
+			return nil, err
+		}
+	case status.RolledBackRevision == updateRevision.Name:
+		if current := findRevision(revisions, status.CurrentRevision); current != nil {
+			target = current
+		} else {
+			status.RolledBackRevision = ""
+		}
+	case findRevision(revisions, status.LastGoodRevision) != nil && r.rolloutFailed(ctx, server, updateRevision):
+		target = findRevision(revisions, status.LastGoodRevision)
+		status.RolledBackRevision = updateRevision.Name
+		log.FromContext(ctx).Info("Revision of MCPServer did not become ready, rolling back",
+			"revision", updateRevision.Revision, "lastGoodRevision", target.Revision)
+	case findRevision(revisions, status.LastGoodRevision) != nil && toolsUnverified(server, updateRevision.Name):
+		// The last good revision keeps serving until the tools of the canary are verified
+		target = findRevision(revisions, status.LastGoodRevision)
+		status.RolledBackRevision = ""
+		status.ToolsCanaryRevision = updateRevision.Name
+	default:
+		status.RolledBackRevision = ""
+	}
+
+	if status.CurrentRevision != target.Name {
+		status.CurrentRevision = target.Name
+		now := metav1.Now()
+		status.RolloutStartTime = &now
+	}
+	if err := r.pruneRevisions(ctx, server, revisions, updateRevision); err != nil {
+		return nil, err
+	}
+
+	deployed := server.DeepCopy()
+	if target.Name != updateRevision.Name {
+		if err := json.Unmarshal(target.Data.Raw, &deployed.Spec); err != nil {
+			return nil, fmt.Errorf("invalid ControllerRevision %s: %w", target.Name, err)
+		}
+	}
+	return deployed, nil
+}
+
+// rolloutFailed reports whether the update revision, being rolled out with
+// rollbackOnFailure, did not become ready within the progress deadline while there is a
+// good revision to roll back to.
+func (r *MCPServerReconciler) rolloutFailed(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+	updateRevision *appsv1.ControllerRevision,
+) bool {
+	status := server.Status
+	if server.Spec.RollbackOnFailure == nil ||
+		status.CurrentRevision != updateRevision.Name ||
+		status.LastGoodRevision == "" ||
+		status.LastGoodRevision == updateRevision.Name ||
+		status.RolloutStartTime == nil ||
+		time.Since(status.RolloutStartTime.Time) < progressDeadline(server) {
+		return false
+	}
+	workload, err := r.getWorkloadStatus(ctx, server)
+	return err == nil && !(workload.rolledOut && workload.revision == updateRevision.Name)
+}
+
+// recordReadyRevision records the current revision as the last good revision once the
+// workload rolled it out. It returns the time left before the rollout of the current
+// revision fails, or zero when no rollback is pending.
+func (r *MCPServerReconciler) recordReadyRevision(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+	deployed *kagentdevv1alpha1.MCPServer,
+) time.Duration {
+	status := &server.Status
+	if status.CurrentRevision == status.LastGoodRevision {
+		return 0
+	}
+	// The workload is only trusted once it records the current revision, since the cache
+	// may still hold the workload of the previous revision
+	workload, err := r.getWorkloadStatus(ctx, deployed)
+	if err == nil && workload.rolledOut && workload.revision == status.CurrentRevision {
+		status.LastGoodRevision = status.CurrentRevision
+		return 0
+	}
+	if server.Spec.RollbackOnFailure == nil || status.RolledBackRevision != "" || status.RolloutStartTime == nil {
+		return 0
+	}
+	return max(time.Until(status.RolloutStartTime.Add(progressDeadline(server))), time.Second)
+}
+
+func progressDeadline(server *kagentdevv1alpha1.MCPServer) time.Duration {
+	if deadline := server.Spec.RollbackOnFailure.ProgressDeadline; deadline != nil {
+		return deadline.Duration
+	}
+	return defaultProgressDeadline
+}
+
+// rollbackTarget returns the revision requested by the rollback annotation: the revision
+// with that number, or the last good revision when the value is empty or 0.
+func rollbackTarget(
+	value string,
+	status *kagentdevv1alpha1.MCPServerStatus,
+	revisions []*appsv1.ControllerRevision,
+) (*appsv1.ControllerRevision, error) {
+	if value == "" || value == "0" {
+		if target := findRevision(revisions, status.LastGoodRevision); target != nil {
+			return target, nil
+		}
+		return nil, fmt.Errorf("no revision of the MCPServer became ready to roll back to")
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation %q: %w", kagentdevv1alpha1.MCPServerRollbackAnnotation, value, err)
+	}
+	for _, revision := range revisions {
+		if revision.Revision == number {
+			return revision, nil
+		}
+	}
+	return nil, fmt.Errorf("revision %d of the MCPServer not found", number)
+}
+
+// removeAnnotation removes an annotation requesting an action once the action is made.
+// The copy of the MCPServer given may carry the defaults of its class and template, so
+// only its resource version is updated from the patched MCPServer.
+func (r *MCPServerReconciler) removeAnnotation(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+	key string,
+) error {
+	patched := server.DeepCopy()
+	patch := client.MergeFrom(patched.DeepCopy())
+	delete(patched.Annotations, key)
+	if err := r.Patch(ctx, patched, patch); err != nil {
+		return err
+	}
+	delete(server.Annotations, key)
+	server.ResourceVersion = patched.ResourceVersion
+	return nil
+}
+
+// annotateRevision records the current revision of the MCPServer on its workload.
+func annotateRevision(outputs []client.Object, revision string) {
+	for _, output := range outputs {
+		switch output.(type) {
+		case *appsv1.Deployment, *appsv1.StatefulSet:
+			annotations := output.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[revisionAnnotation] = revision
+			output.SetAnnotations(annotations)
+		}
+	}
+}
+
+// listRevisions returns the ControllerRevisions of the MCPServer, oldest first.
+func (r *MCPServerReconciler) listRevisions(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+) ([]*appsv1.ControllerRevision, error) {
+	list := &appsv1.ControllerRevisionList{}
+	if err := r.List(ctx, list,
+		client.InNamespace(server.Namespace),
+		client.MatchingLabels{kagentdevv1alpha1.MCPServerNameLabel: server.Name},
+	); err != nil {
+		return nil, err
+	}
+
+	revisions := make([]*appsv1.ControllerRevision, 0, len(list.Items))
+	for i := range list.Items {
+		if isOwnedBy(&list.Items[i], server) {
+			revisions = append(revisions, &list.Items[i])
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+// ensureRevision returns the ControllerRevision holding the spec of the MCPServer,
+// creating it when the spec is new. A revision whose spec is restored becomes the newest
+// revision again, like the revisions of StatefulSets.
+func (r *MCPServerReconciler) ensureRevision(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+	revisions []*appsv1.ControllerRevision,
+) (*appsv1.ControllerRevision, error) {
+	spec := server.Spec.DeepCopy()
+	// The rollback and tool pinning settings do not change what is deployed
+	spec.RollbackOnFailure = nil
+	spec.RevisionHistoryLimit = nil
+	spec.ToolPinning = nil
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the spec of MCPServer %s: %w", server.Name, err)
+	}
+	hasher := fnv.New32a()
+	hasher.Write(data) //nolint:errcheck // hash writes never fail
+	name := fmt.Sprintf("%s-%s", server.Name, rand.SafeEncodeString(strconv.FormatUint(uint64(hasher.Sum32()), 10)))
+
+	var latest int64
+	if len(revisions) > 0 {
+		latest = revisions[len(revisions)-1].Revision
+	}
+	if existing := findRevision(revisions, name); existing != nil {
+		if existing.Revision != latest {
+			existing.Revision = latest + 1
+			if err := r.Update(ctx, existing); err != nil {
+				return nil, err
+			}
+		}
+		return existing, nil
+	}
+
+	revision := &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: server.Namespace,
+			Labels:    map[string]string{kagentdevv1alpha1.MCPServerNameLabel: server.Name},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: latest + 1,
+	}
+	if err := controllerutil.SetControllerReference(server, revision, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// pruneRevisions deletes the oldest revisions beyond the revision history limit. The
+// update, current and last good revisions are kept.
+func (r *MCPServerReconciler) pruneRevisions(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+	revisions []*appsv1.ControllerRevision,
+	updateRevision *appsv1.ControllerRevision,
+) error {
+	limit := defaultRevisionHistoryLimit
+	if server.Spec.RevisionHistoryLimit != nil {
+		limit = int(*server.Spec.RevisionHistoryLimit)
+	}
+
+	var old []*appsv1.ControllerRevision
+	for _, revision := range revisions {
+		switch revision.Name {
+		case updateRevision.Name, server.Status.CurrentRevision, server.Status.LastGoodRevision:
+		default:
+			old = append(old, revision)
+		}
+	}
+	sort.Slice(old, func(i, j int) bool { return old[i].Revision < old[j].Revision })
+	for i := 0; i < len(old)-limit; i++ {
+		if err := r.Delete(ctx, old[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findRevision(revisions []*appsv1.ControllerRevision, name string) *appsv1.ControllerRevision {
+	if name == "" {
+		return nil
+	}
+	for _, revision := range revisions {
+		if revision.Name == name {
+			return revision
+		}
+	}
+	return nil
+}
+
+// replicasOf returns the desired replicas of a workload, which default to 1.
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
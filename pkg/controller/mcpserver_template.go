@@ -0,0 +1,107 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/kagent-dev/kmcp/pkg/controller/transportadapter"
+)
+
+// errServerTemplate marks the errors caused by the MCPServerTemplate of an MCPServer, which
+// are reported as configuration errors on the Accepted condition.
+var errServerTemplate = errors.New("invalid MCPServerTemplate instance")
+
+// expandServerTemplate returns the MCPServer expanded from its MCPServerTemplate, or the
+// MCPServer itself when it does not reference a template. It is used by every reconciler
+// reading the spec of MCPServers, since the spec of an instance is the expanded template.
+func expandServerTemplate(
+	ctx context.Context,
+	c client.Reader,
+	server *kagentdevv1alpha1.MCPServer,
+) (*kagentdevv1alpha1.MCPServer, error) {
+	if server.Spec.TemplateRef == nil {
+		server = server.DeepCopy()
+		server.Status.TemplateGeneration = 0
+		return server, nil
+	}
+
+	template := &kagentdevv1alpha1.MCPServerTemplate{}
+	if err := c.Get(ctx, client.ObjectKey{Name: server.Spec.TemplateRef.Name}, template); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: MCPServerTemplate %s not found", errServerTemplate, server.Spec.TemplateRef.Name)
+		}
+		return nil, err
+	}
+	expanded, err := transportadapter.ExpandServerTemplate(server, template)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errServerTemplate, err)
+	}
+	return expanded, nil
+}
+
+// validateExpandedServer validates the spec expanded from the MCPServerTemplate of the
+// MCPServer against the MCPServer schema with a dry-run update, since the template itself
+// is schemaless. It returns the expanded MCPServer with the defaults of the schema applied.
+func validateExpandedServer(
+	ctx context.Context,
+	c client.Writer,
+	server *kagentdevv1alpha1.MCPServer,
+	expanded *kagentdevv1alpha1.MCPServer,
+) (*kagentdevv1alpha1.MCPServer, error) {
+	if server.Spec.TemplateRef == nil {
+		return expanded, nil
+	}
+	candidate := server.DeepCopy()
+	candidate.Spec = *expanded.Spec.DeepCopy()
+	if err := c.Update(ctx, candidate, client.DryRunAll); err != nil {
+		if apierrors.IsInvalid(err) {
+			return nil, fmt.Errorf("%w: the expanded MCPServerTemplate %s is not a valid MCPServer: %w",
+				errServerTemplate, server.Spec.TemplateRef.Name, err)
+		}
+		return nil, err
+	}
+	validated := expanded.DeepCopy()
+	validated.Spec = candidate.Spec
+	return validated, nil
+}
+
+// serversForTemplate maps an MCPServerTemplate to its instances, so that changes to the
+// template roll out to all of them.
+func (r *MCPServerReconciler) serversForTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	servers := &kagentdevv1alpha1.MCPServerList{}
+	if err := r.List(ctx, servers); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list MCPServers")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, server := range servers.Items {
+		if server.Spec.TemplateRef != nil && server.Spec.TemplateRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&server)})
+		}
+	}
+	return requests
+}
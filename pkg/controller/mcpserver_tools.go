@@ -0,0 +1,518 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/kagent-dev/kmcp/pkg/controller/config"
+	"github.com/kagent-dev/kmcp/pkg/controller/transportadapter"
+)
+
+// listToolsTimeout bounds the connection to an MCP server listing its tools.
+const listToolsTimeout = 30 * time.Second
+
+// ToolLister lists the tools served by a deployed MCPServer.
+type ToolLister func(ctx context.Context, server *kagentdevv1alpha1.MCPServer) ([]mcp.Tool, error)
+
+// listServerTools lists the tools of the MCPServer through the endpoint its Service serves.
+func listServerTools(ctx context.Context, server *kagentdevv1alpha1.MCPServer) ([]mcp.Tool, error) {
+	ctx, cancel := context.WithTimeout(ctx, listToolsTimeout)
+	defer cancel()
+
+	mcpClient, err := newServerClient(transportadapter.ServerEndpoints(server))
+	if err != nil {
+		return nil, err
+	}
+	defer mcpClient.Close() //nolint:errcheck // the session is discarded
+	if err := mcpClient.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to the MCP server: %w", err)
+	}
+
+	if _, err := mcpClient.Initialize(ctx, mcp.InitializeRequest{
+		Params: mcp.InitializeParams{
+			ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+			ClientInfo: mcp.Implementation{
+				Name:    "kmcp-controller",
+				Version: "1.0.0",
+			},
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to initialize the MCP session: %w", err)
+	}
+	result, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tools: %w", err)
+	}
+	return result.Tools, nil
+}
+
+// newServerClient returns a client of the Streamable HTTP endpoint of the MCPServer, or
+// of its HTTP+SSE endpoint when the server only serves HTTP+SSE.
+func newServerClient(endpoints kagentdevv1alpha1.MCPServerEndpoints) (*mcpclient.Client, error) {
+	if endpoints.StreamableHTTP != "" {
+		return mcpclient.NewStreamableHttpClient(endpoints.StreamableHTTP)
+	}
+	return mcpclient.NewSSEMCPClient(endpoints.SSE)
+}
+
+// toolsCanarySuffix is appended to the name of an MCPServer to name the workload,
+// Service and ConfigMap of its tools canary.
+const toolsCanarySuffix = "-tools-canary"
+
+// approveTools pins the changed tools of the MCPServer when the approve annotation holds
+// their digest. A revision blocked because of the changed tools is rolled out, and a
+// workload scaled down because of them is scaled up again.
+func (r *MCPServerReconciler) approveTools(ctx context.Context, server *kagentdevv1alpha1.MCPServer) error {
+	approval, ok := server.Annotations[kagentdevv1alpha1.MCPServerApproveToolsAnnotation]
+	status := &server.Status
+	if !ok || server.Spec.ToolPinning == nil || len(status.ChangedTools) == 0 {
+		return nil
+	}
+	if approval != toolsDigest(status.ChangedTools) {
+		log.FromContext(ctx).Info("Ignoring the approval of tools not matching the changed tools", "approval", approval)
+		return nil
+	}
+
+	status.PinnedTools = status.ChangedTools
+	status.ChangedTools = nil
+	// The tools of the blocked revision were verified, so it is rolled out without a canary
+	if status.RolledBackRevision != "" && status.RolledBackRevision == status.ToolsCheckedRevision {
+		status.RolledBackRevision = ""
+	}
+	r.Recorder.Event(server, corev1.EventTypeNormal, string(kagentdevv1alpha1.MCPServerReasonToolsPinned),
+		"Changed tools approved")
+	return r.removeAnnotation(ctx, server, kagentdevv1alpha1.MCPServerApproveToolsAnnotation)
+}
+
+// verifyTools compares the tools served by the MCPServer to its pinned tools. The tools
+// of a revision held back by the block policy are listed on a canary; the tools of the
+// workload are listed once it rolled out a revision that was not checked, when its pods
+// restarted since the last check, and at the toolsRecheck interval. The tools of the
+// first revision are pinned. verifyTools reports whether the MCPServer is to be
+// reconciled again to roll out or hold back the verified revision, or to scale down a
+// workload whose tools changed.
+func (r *MCPServerReconciler) verifyTools(
+	ctx context.Context,
+	cfg *config.ControllerConfig,
+	server *kagentdevv1alpha1.MCPServer,
+	deployed *kagentdevv1alpha1.MCPServer,
+) (bool, error) {
+	status := &server.Status
+	if server.Spec.ToolPinning == nil {
+		status.PinnedTools = nil
+		status.ChangedTools = nil
+		status.ToolsCheckedRevision = ""
+		status.ToolsCheckTime = nil
+		return false, r.deleteToolsCanary(ctx, server)
+	}
+	if status.ToolsCanaryRevision != "" {
+		return r.verifyToolsCanary(ctx, cfg, server)
+	}
+	if err := r.deleteToolsCanary(ctx, server); err != nil {
+		return false, err
+	}
+	// The revision rolled back to was already checked, and a workload scaled down for
+	// its changed tools serves none
+	if status.RolledBackRevision != "" || toolsBlocked(server) {
+		return false, nil
+	}
+	if status.ToolsCheckedRevision == status.CurrentRevision && !r.toolsRecheckDue(ctx, cfg, server) {
+		return false, nil
+	}
+	workload, err := r.getWorkloadStatus(ctx, deployed)
+	if err != nil || !workload.rolledOut || workload.revision != status.CurrentRevision {
+		return false, nil
+	}
+
+	hashes, err := r.listToolHashes(ctx, deployed)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list the tools of MCPServer")
+		return false, nil
+	}
+	return r.compareTools(ctx, server, status.CurrentRevision, hashes), nil
+}
+
+// verifyToolsCanary deploys the canary revision of the MCPServer as a single replica
+// Deployment, and compares its tools to the pinned tools once it rolled out. The canary
+// is deleted once its tools are compared.
+func (r *MCPServerReconciler) verifyToolsCanary(
+	ctx context.Context,
+	cfg *config.ControllerConfig,
+	server *kagentdevv1alpha1.MCPServer,
+) (bool, error) {
+	revision := server.Status.ToolsCanaryRevision
+	canary := toolsCanary(server)
+	outputs, err := r.translateOutputs(ctx, cfg, canary)
+	if err != nil {
+		return false, fmt.Errorf("failed to translate the tools canary: %w", err)
+	}
+	outputs = toolsCanaryOutputs(server, outputs, server.Spec.Deployment.VolumeClaimTemplates)
+	annotateRevision(outputs, revision)
+	if err := r.reconcileOutputs(ctx, server, outputs); err != nil {
+		return false, err
+	}
+
+	workload, err := r.getWorkloadStatus(ctx, canary)
+	if err != nil || !workload.rolledOut || workload.revision != revision {
+		return false, nil
+	}
+	hashes, err := r.listToolHashes(ctx, canary)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list the tools of the MCPServer canary")
+		return false, nil
+	}
+	r.compareTools(ctx, server, revision, hashes)
+	return true, r.deleteToolsCanary(ctx, server)
+}
+
+// compareTools records the tools served by a revision of the MCPServer, pinning them when
+// no tools are pinned yet. With the block policy, a canary revision serving changed tools
+// is recorded as rolled back, and compareTools reports that the current revision serves
+// changed tools.
+func (r *MCPServerReconciler) compareTools(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+	revision string,
+	hashes []kagentdevv1alpha1.ToolHash,
+) bool {
+	status := &server.Status
+	status.ToolsCheckedRevision = revision
+	now := metav1.Now()
+	status.ToolsCheckTime = &now
+
+	switch {
+	case len(status.PinnedTools) == 0:
+		status.PinnedTools = hashes
+		r.Recorder.Eventf(server, corev1.EventTypeNormal, string(kagentdevv1alpha1.MCPServerReasonToolsPinned),
+			"Pinned %d tools", len(hashes))
+	case toolsDigest(hashes) == toolsDigest(status.PinnedTools):
+		status.ChangedTools = nil
+	default:
+		// The same changes listed again on a recheck are only reported once
+		if toolsDigest(hashes) != toolsDigest(status.ChangedTools) {
+			r.Recorder.Event(server, corev1.EventTypeWarning, string(kagentdevv1alpha1.MCPServerReasonToolsChanged),
+				describeToolChanges(status.PinnedTools, hashes))
+		}
+		status.ChangedTools = hashes
+		if server.Spec.ToolPinning.Policy != kagentdevv1alpha1.ToolPinningPolicyBlock {
+			return false
+		}
+		if revision != status.CurrentRevision {
+			log.FromContext(ctx).Info("Tools of MCPServer canary changed, not rolling out",
+				"revision", revision, "currentRevision", status.CurrentRevision)
+			status.RolledBackRevision = revision
+			return false
+		}
+		log.FromContext(ctx).Info("Tools of MCPServer changed, scaling down", "revision", revision)
+		return true
+	}
+	return false
+}
+
+// listToolHashes lists and hashes the tools of the MCPServer.
+func (r *MCPServerReconciler) listToolHashes(
+	ctx context.Context,
+	server *kagentdevv1alpha1.MCPServer,
+) ([]kagentdevv1alpha1.ToolHash, error) {
+	listTools := r.ListTools
+	if listTools == nil {
+		listTools = listServerTools
+	}
+	tools, err := listTools(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	return hashTools(tools)
+}
+
+// toolsRecheckDue reports whether the tools of the workload of the MCPServer are to be
+// listed again: when the toolsRecheck interval elapsed, or a pod became ready since the
+// last check, since a package that is not pinned to a version may change on a restart.
+func (r *MCPServerReconciler) toolsRecheckDue(
+	ctx context.Context,
+	cfg *config.ControllerConfig,
+	server *kagentdevv1alpha1.MCPServer,
+) bool {
+	checked := server.Status.ToolsCheckTime
+	if checked == nil || time.Since(checked.Time) >= cfg.Requeue.ToolsRecheck.Duration {
+		return true
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods,
+		client.InNamespace(server.Namespace),
+		client.MatchingLabels{kagentdevv1alpha1.MCPServerNameLabel: server.Name},
+	); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list the pods of MCPServer")
+		return false
+	}
+	for _, pod := range pods.Items {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue &&
+				condition.LastTransitionTime.After(checked.Time) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toolsRecheckIn returns the time left before the tools of the MCPServer are listed
+// again, or zero when they are not listed on an interval.
+func toolsRecheckIn(cfg *config.ControllerConfig, server *kagentdevv1alpha1.MCPServer) time.Duration {
+	checked := server.Status.ToolsCheckTime
+	if server.Spec.ToolPinning == nil || checked == nil || toolsBlocked(server) {
+		return 0
+	}
+	return max(time.Until(checked.Add(cfg.Requeue.ToolsRecheck.Duration)), time.Second)
+}
+
+// toolsUnverified reports whether a revision of the MCPServer is to be verified on a
+// canary before it is rolled out: the block policy holds back the revisions whose tools
+// were not checked, once a revision became ready.
+func toolsUnverified(server *kagentdevv1alpha1.MCPServer, revision string) bool {
+	status := server.Status
+	return server.Spec.ToolPinning != nil &&
+		server.Spec.ToolPinning.Policy == kagentdevv1alpha1.ToolPinningPolicyBlock &&
+		status.ToolsCheckedRevision != revision &&
+		status.CurrentRevision != revision &&
+		status.LastGoodRevision != revision
+}
+
+// toolsBlocked reports whether the workload of the MCPServer is scaled down because the
+// tools of its current revision changed under the block policy.
+func toolsBlocked(server *kagentdevv1alpha1.MCPServer) bool {
+	status := server.Status
+	return server.Spec.ToolPinning != nil &&
+		server.Spec.ToolPinning.Policy == kagentdevv1alpha1.ToolPinningPolicyBlock &&
+		len(status.ChangedTools) > 0 &&
+		status.RolledBackRevision == "" &&
+		status.ToolsCanaryRevision == "" &&
+		status.ToolsCheckedRevision == status.CurrentRevision
+}
+
+// suspendWorkload scales the workload among the outputs to zero replicas.
+func suspendWorkload(outputs []client.Object) {
+	for _, output := range outputs {
+		var replicas int32
+		switch workload := output.(type) {
+		case *appsv1.Deployment:
+			workload.Spec.Replicas = &replicas
+		case *appsv1.StatefulSet:
+			workload.Spec.Replicas = &replicas
+		}
+	}
+}
+
+// toolsPending reports whether the tools of the current revision of the MCPServer, or of
+// its canary revision, are still to be compared to its pinned tools.
+func toolsPending(server *kagentdevv1alpha1.MCPServer) bool {
+	status := server.Status
+	return server.Spec.ToolPinning != nil &&
+		(status.ToolsCanaryRevision != "" ||
+			status.RolledBackRevision == "" && status.ToolsCheckedRevision != status.CurrentRevision)
+}
+
+// toolsCanary returns a copy of the MCPServer deploying its spec as the tools canary: a
+// single replica Deployment, with a ClusterIP Service and the ServiceAccount of the
+// MCPServer.
+func toolsCanary(server *kagentdevv1alpha1.MCPServer) *kagentdevv1alpha1.MCPServer {
+	canary := server.DeepCopy()
+	canary.Name = server.Name + toolsCanarySuffix
+	canary.Spec.Service = nil
+	canary.Spec.Observability = nil
+	deployment := &canary.Spec.Deployment
+	if deployment.ServiceAccountName == "" {
+		deployment.ServiceAccountName = server.Name
+	}
+	deployment.WorkloadKind = kagentdevv1alpha1.WorkloadKindDeployment
+	deployment.VolumeClaimTemplates = nil
+	replicas := int32(1)
+	deployment.Replicas = &replicas
+	return canary
+}
+
+// toolsCanaryOutputs keeps the Deployment, Service and ConfigMap among the outputs of
+// the tools canary, owned by the MCPServer so that their changes enqueue it. The volume
+// claims of the MCPServer are replaced by empty directories, so that the canary does
+// not claim volumes.
+func toolsCanaryOutputs(
+	server *kagentdevv1alpha1.MCPServer,
+	outputs []client.Object,
+	claims []corev1.PersistentVolumeClaim,
+) []client.Object {
+	kept := make([]client.Object, 0, 3)
+	for _, output := range outputs {
+		switch output := output.(type) {
+		case *appsv1.Deployment:
+			for _, claim := range claims {
+				output.Spec.Template.Spec.Volumes = append(output.Spec.Template.Spec.Volumes, corev1.Volume{
+					Name:         claim.Name,
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				})
+			}
+		case *corev1.Service, *corev1.ConfigMap:
+		default:
+			continue
+		}
+		refs := output.GetOwnerReferences()
+		for i := range refs {
+			if refs[i].UID == server.UID {
+				refs[i].Name = server.Name
+			}
+		}
+		output.SetOwnerReferences(refs)
+		kept = append(kept, output)
+	}
+	return kept
+}
+
+// deleteToolsCanary deletes the tools canary of the MCPServer. The Deployment is deleted
+// last, so that a canary without a Deployment has nothing left to delete.
+func (r *MCPServerReconciler) deleteToolsCanary(ctx context.Context, server *kagentdevv1alpha1.MCPServer) error {
+	key := client.ObjectKey{Name: server.Name + toolsCanarySuffix, Namespace: server.Namespace}
+	if err := r.Get(ctx, key, &appsv1.Deployment{}); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	for _, obj := range []client.Object{&corev1.Service{}, &corev1.ConfigMap{}, &appsv1.Deployment{}} {
+		if err := r.Get(ctx, key, obj); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		if !isOwnedBy(obj, server) {
+			continue
+		}
+		if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setToolsChangedCondition sets the ToolsChanged condition from the pinned and changed
+// tools of the MCPServer.
+func setToolsChangedCondition(server *kagentdevv1alpha1.MCPServer) {
+	status := &server.Status
+	switch {
+	case server.Spec.ToolPinning == nil:
+		meta.RemoveStatusCondition(&status.Conditions, string(kagentdevv1alpha1.MCPServerConditionToolsChanged))
+	case len(status.ChangedTools) > 0:
+		setCondition(server, kagentdevv1alpha1.MCPServerConditionToolsChanged, metav1.ConditionTrue,
+			kagentdevv1alpha1.MCPServerReasonToolsChanged,
+			fmt.Sprintf("%s; approve with the annotation %s=%s",
+				describeToolChanges(status.PinnedTools, status.ChangedTools),
+				kagentdevv1alpha1.MCPServerApproveToolsAnnotation, toolsDigest(status.ChangedTools)),
+		)
+	case len(status.PinnedTools) > 0:
+		setCondition(server, kagentdevv1alpha1.MCPServerConditionToolsChanged, metav1.ConditionFalse,
+			kagentdevv1alpha1.MCPServerReasonToolsPinned,
+			fmt.Sprintf("%d tools match the pinned tools", len(status.PinnedTools)),
+		)
+	}
+}
+
+// hashTools returns the hashes of the tools, sorted by name. The input schema is hashed
+// in its canonical JSON encoding, so that the order of its keys does not matter.
+func hashTools(tools []mcp.Tool) ([]kagentdevv1alpha1.ToolHash, error) {
+	hashes := make([]kagentdevv1alpha1.ToolHash, 0, len(tools))
+	for _, tool := range tools {
+		raw, err := json.Marshal(tool)
+		if err != nil {
+			return nil, err
+		}
+		var schema struct {
+			InputSchema interface{} `json:"inputSchema"`
+		}
+		if err := json.Unmarshal(raw, &schema); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal([]interface{}{tool.Name, tool.Description, schema.InputSchema})
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		hashes = append(hashes, kagentdevv1alpha1.ToolHash{Name: tool.Name, Hash: hex.EncodeToString(sum[:])})
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i].Name < hashes[j].Name })
+	return hashes, nil
+}
+
+// toolsDigest returns a short digest identifying a set of tool hashes.
+func toolsDigest(hashes []kagentdevv1alpha1.ToolHash) string {
+	sorted := append([]kagentdevv1alpha1.ToolHash(nil), hashes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	digest := sha256.New()
+	for _, hash := range sorted {
+		fmt.Fprintf(digest, "%s:%s\n", hash.Name, hash.Hash)
+	}
+	return hex.EncodeToString(digest.Sum(nil))[:16]
+}
+
+// describeToolChanges lists the tools changed, added and removed between the pinned
+// tools and the served tools.
+func describeToolChanges(pinned, served []kagentdevv1alpha1.ToolHash) string {
+	pinnedHashes := make(map[string]string, len(pinned))
+	for _, hash := range pinned {
+		pinnedHashes[hash.Name] = hash.Hash
+	}
+	var changed, added []string
+	for _, hash := range served {
+		pinnedHash, ok := pinnedHashes[hash.Name]
+		switch {
+		case !ok:
+			added = append(added, hash.Name)
+		case pinnedHash != hash.Hash:
+			changed = append(changed, hash.Name)
+		}
+		delete(pinnedHashes, hash.Name)
+	}
+	removed := make([]string, 0, len(pinnedHashes))
+	for name := range pinnedHashes {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+
+	var changes []string
+	for _, group := range []struct {
+		verb  string
+		names []string
+	}{{"changed", changed}, {"added", added}, {"removed", removed}} {
+		if len(group.names) > 0 {
+			changes = append(changes, fmt.Sprintf("%s %s", group.verb, strings.Join(group.names, ", ")))
+		}
+	}
+	return "Tools differ from the pinned tools: " + strings.Join(changes, "; ")
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+	"github.com/kagent-dev/kmcp/pkg/controller/config"
+)
+
+// +kubebuilder:webhook:path=/validate-kagent-dev-v1alpha1-mcpserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=kagent.dev,resources=mcpservers,verbs=create;update,versions=v1alpha1,name=vmcpserver-v1alpha1.kagent.dev,admissionReviewVersions=v1
+
+// MCPServerValidator rejects the MCPServers violating an enforced MCPServerPolicy at
+// admission, and warns about the violations of audited policies. The policies are
+// checked again by the MCPServerReconciler.
+type MCPServerValidator struct {
+	Client client.Client
+	// AdapterBackend is the adapter backend of the controller, see MCPServerReconciler.
+	AdapterBackend string
+	// Config is the configuration of the controller, see MCPServerReconciler.
+	Config *config.Watcher
+}
+
+var _ admission.CustomValidator = &MCPServerValidator{}
+
+// SetupWebhookWithManager registers the webhook with the Manager.
+func (v *MCPServerValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kagentdevv1alpha1.MCPServer{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate checks a created MCPServer against the MCPServerPolicies.
+func (v *MCPServerValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate checks an updated MCPServer against the MCPServerPolicies.
+func (v *MCPServerValidator) ValidateUpdate(
+	ctx context.Context,
+	_ runtime.Object,
+	newObj runtime.Object,
+) (admission.Warnings, error) {
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete allows MCPServers to be deleted.
+func (v *MCPServerValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *MCPServerValidator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	server, ok := obj.(*kagentdevv1alpha1.MCPServer)
+	if !ok {
+		return nil, fmt.Errorf("expected an MCPServer, got %T", obj)
+	}
+
+	// The policies apply to the MCPServer as deployed. A missing template or class may be
+	// created later, so the MCPServer is admitted and checked when it is reconciled.
+	defaults := v.Config.Current().TranslationDefaults()
+	expanded, err := expandServerTemplate(ctx, v.Client, server)
+	if err == nil {
+		expanded, err = applyServerClass(ctx, v.Client, expanded, v.AdapterBackend, defaults)
+	}
+	if err != nil {
+		if isReferenceError(err) {
+			log.FromContext(ctx).Info("MCPServerPolicies not checked at admission", "mcpserver", server.Name, "reason", err)
+			return admission.Warnings{"MCPServerPolicies are checked when the MCPServer is reconciled: " + err.Error()}, nil
+		}
+		return nil, err
+	}
+
+	warnings, err := checkServerPolicies(ctx, v.Client, expanded, defaults)
+	return admission.Warnings(warnings), err
+}
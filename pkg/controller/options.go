@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultBackoffBase is the delay before a failed reconcile is retried for the first time.
+	DefaultBackoffBase = 100 * time.Millisecond
+	// DefaultBackoffMax caps the delay between the retries of a failing reconcile.
+	DefaultBackoffMax = 5 * time.Minute
+)
+
+// ReconcileOptions configure how the controllers work through their queues.
+type ReconcileOptions struct {
+	// MaxConcurrentReconciles is the number of resources reconciled in parallel.
+	// Defaults to 1.
+	MaxConcurrentReconciles int
+	// BackoffBase is the delay before a failed reconcile is retried, doubled on each
+	// consecutive failure of the same resource. Defaults to DefaultBackoffBase.
+	BackoffBase time.Duration
+	// BackoffMax caps the delay between retries. Defaults to DefaultBackoffMax.
+	BackoffMax time.Duration
+}
+
+// controllerOptions returns the controller-runtime options of a controller.
+func (o ReconcileOptions) controllerOptions() crcontroller.Options {
+	base, maxDelay := o.BackoffBase, o.BackoffMax
+	if base <= 0 {
+		base = DefaultBackoffBase
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultBackoffMax
+	}
+	return crcontroller.Options{
+		MaxConcurrentReconciles: o.MaxConcurrentReconciles,
+		// Failures are retried with a per-resource exponential backoff, and the overall
+		// retry rate is limited like with the default rate limiter of controller-runtime
+		RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](base, maxDelay),
+			&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+		),
+	}
+}
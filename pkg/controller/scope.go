@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// Scope selects the MCPServers and MCPGateways reconciled by a controller replica.
+// Resources out of scope are left as they are.
+type Scope struct {
+	// NamespaceSelector selects the namespaces of the reconciled resources by their
+	// labels. All namespaces are selected when nil.
+	NamespaceSelector labels.Selector
+	// Shards is the number of controller shards splitting the resources. The controller
+	// is not sharded when it is 0 or 1.
+	Shards int
+	// Shard is the number of the shard of this controller, from 0 to Shards-1.
+	Shard int
+}
+
+// Validate checks the shard settings.
+func (s Scope) Validate() error {
+	if s.Shards < 0 {
+		return fmt.Errorf("the number of shards must not be negative, got %d", s.Shards)
+	}
+	if s.Shard < 0 || s.Shard >= max(s.Shards, 1) {
+		return fmt.Errorf("the shard must be between 0 and %d, got %d", max(s.Shards, 1)-1, s.Shard)
+	}
+	return nil
+}
+
+// Includes reports whether the resource is in the scope: its namespace is selected and
+// it is assigned to the shard.
+func (s Scope) Includes(ctx context.Context, c client.Reader, obj client.Object) (bool, error) {
+	if !s.ownsShard(obj) {
+		return false, nil
+	}
+	if !s.selectsNamespaces() {
+		return true, nil
+	}
+	namespace := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: obj.GetNamespace()}, namespace); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return s.NamespaceSelector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// ownsShard reports whether the resource is assigned to the shard, by its shard label or
+// else by the hash of its namespace and name.
+func (s Scope) ownsShard(obj client.Object) bool {
+	if s.Shards <= 1 {
+		return true
+	}
+	if value, ok := obj.GetLabels()[kagentdevv1alpha1.ShardLabel]; ok {
+		if shard, err := strconv.Atoi(value); err == nil && shard >= 0 && shard < s.Shards {
+			return shard == s.Shard
+		}
+	}
+	hasher := fnv.New32a()
+	hasher.Write([]byte(obj.GetNamespace() + "/" + obj.GetName())) //nolint:errcheck // hash writes never fail
+	return int(hasher.Sum32()%uint32(s.Shards)) == s.Shard
+}
+
+// selectsNamespaces reports whether namespaces are selected by their labels, in which
+// case namespace label changes bring resources in or out of scope.
+func (s Scope) selectsNamespaces() bool {
+	return s.NamespaceSelector != nil && !s.NamespaceSelector.Empty()
+}
+
+// requestsInNamespace returns a function enqueuing the resources of the list type in a
+// namespace, once its labels changed.
+func requestsInNamespace(c client.Reader, newList func() client.ObjectList) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		return listRequests(ctx, c, newList(), client.InNamespace(obj.GetName()))
+	}
+}
+
+// listRequests lists the resources of the list type and returns their requests.
+func listRequests(
+	ctx context.Context,
+	c client.Reader,
+	list client.ObjectList,
+	opts ...client.ListOption,
+) []reconcile.Request {
+	if err := c.List(ctx, list, opts...); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list resources")
+		return nil
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to extract list")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(items))
+	for _, item := range items {
+		if o, ok := item.(client.Object); ok {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)})
+		}
+	}
+	return requests
+}
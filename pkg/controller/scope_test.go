@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kagentdevv1alpha1 "github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+var _ = ginkgo.Describe("Controller scope", func() {
+	ctx := context.Background()
+
+	ginkgo.It("should assign every MCPServer to exactly one shard", func() {
+		const shards = 3
+		counts := make([]int, shards)
+		for i := range 30 {
+			server := newBenchmarkServer(fmt.Sprintf("server-%d", i))
+			owners := 0
+			for shard := range shards {
+				inScope, err := Scope{Shards: shards, Shard: shard}.Includes(ctx, k8sClient, server)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				if inScope {
+					owners++
+					counts[shard]++
+				}
+			}
+			gomega.Expect(owners).To(gomega.Equal(1))
+		}
+		for _, count := range counts {
+			gomega.Expect(count).To(gomega.BeNumerically(">", 0))
+		}
+
+		ginkgo.By("assigning labeled MCPServers to the shard of their label")
+		server := newBenchmarkServer("server-labeled")
+		server.Labels = map[string]string{kagentdevv1alpha1.ShardLabel: "2"}
+		for shard := range shards {
+			inScope, err := Scope{Shards: shards, Shard: shard}.Includes(ctx, k8sClient, server)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(inScope).To(gomega.Equal(shard == 2))
+		}
+	})
+
+	ginkgo.It("should only reconcile the MCPServers of the selected namespaces", func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-scope-tenant"}}
+		gomega.Expect(k8sClient.Create(ctx, namespace)).To(gomega.Succeed())
+		ginkgo.DeferCleanup(func() {
+			gomega.Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, namespace))).To(gomega.Succeed())
+		})
+
+		server := newBenchmarkServer("test-scope-server")
+		server.Namespace = namespace.Name
+		gomega.Expect(k8sClient.Create(ctx, server)).To(gomega.Succeed())
+
+		selector, err := labels.Parse("kmcp.kagent.dev/tenant")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		reconciler := &MCPServerReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Scope:  Scope{NamespaceSelector: selector},
+		}
+		request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(server)}
+
+		ginkgo.By("leaving the MCPServer of an unselected namespace untouched")
+		_, err = reconciler.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		deployment := &appsv1.Deployment{}
+		err = k8sClient.Get(ctx, request.NamespacedName, deployment)
+		gomega.Expect(errors.IsNotFound(err)).To(gomega.BeTrue())
+
+		ginkgo.By("reconciling it once the namespace is labeled")
+		namespace.Labels = map[string]string{"kmcp.kagent.dev/tenant": "a"}
+		gomega.Expect(k8sClient.Update(ctx, namespace)).To(gomega.Succeed())
+		_, err = reconciler.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(k8sClient.Get(ctx, request.NamespacedName, deployment)).To(gomega.Succeed())
+	})
+})
@@ -0,0 +1,224 @@
+package transportadapter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+const (
+	// AgentgatewayBackendName is the name of the agentgateway adapter backend, which is the default.
+	AgentgatewayBackendName = "agentgateway"
+
+	// MCPProxyBackendName is the name of the mcp-proxy adapter backend.
+	MCPProxyBackendName = "mcp-proxy"
+
+	// agentgatewayReadinessPort is the port of the agentgateway readiness endpoint.
+	agentgatewayReadinessPort = 15021
+
+	transportAdapterRepository     = "ghcr.io/agentgateway/agentgateway"
+	defaultTransportAdapterVersion = "0.9.0"
+	mcpProxyImage                  = "ghcr.io/sparfenyuk/mcp-proxy:v0.8.2"
+)
+
+// versionRegex validates that version strings contain only allowed characters
+// (alphanumeric, dots, hyphens) to prevent potential image injection attacks
+var versionRegex = regexp.MustCompile(`^[a-zA-Z0-9.\-]+$`)
+
+// AdapterBackend is a stdio-to-HTTP bridge that exposes a stdio MCP server over HTTP.
+// A backend covers the pod wiring of the adapter, the rendering of its configuration
+// and the protocol advertised on the Service.
+type AdapterBackend interface {
+	// Name returns the name used to select the backend.
+	Name() string
+
+	// Image returns the default container image of the adapter.
+	Image(defaults Defaults) string
+
+	// SupportsCopyBinary reports whether the adapter binary can be copied into
+	// another container by an init container running the adapter image.
+	SupportsCopyBinary() bool
+
+	// CopyArgs returns the arguments of the init container that copies the adapter
+	// binary into binDir. It is only called when SupportsCopyBinary returns true.
+	CopyArgs(binDir string) []string
+
+	// Command returns the command and arguments that start the adapter. binDir is the
+	// directory the adapter binary was copied to, or empty when the adapter runs from
+	// its own image. The adapter must spawn the stdio process described by target.
+	Command(binDir string, server *v1alpha1.MCPServer, target *StdioTargetSpec) ([]string, []string)
+
+	// RenderConfig renders the configuration files of the adapter, keyed by file name.
+	// The files are stored in the MCPServer ConfigMap, which is mounted at /config.
+	RenderConfig(server *v1alpha1.MCPServer, target *StdioTargetSpec) (map[string]string, error)
+
+	// AppProtocol returns the appProtocol of the Service port, or nil to leave it unset.
+	AppProtocol(defaults Defaults) *string
+
+	// ReadinessProbe returns the handler of the default readiness probe of the adapter.
+	ReadinessProbe(server *v1alpha1.MCPServer) corev1.ProbeHandler
+}
+
+var adapterBackends = map[string]AdapterBackend{
+	AgentgatewayBackendName: agentgatewayBackend{},
+	MCPProxyBackendName:     mcpProxyBackend{},
+}
+
+// LookupAdapterBackend returns the adapter backend registered under the given name.
+func LookupAdapterBackend(name string) (AdapterBackend, error) {
+	backend, ok := adapterBackends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown transport adapter backend %q, must be one of %v", name, AdapterBackendNames())
+	}
+	return backend, nil
+}
+
+// AdapterBackendNames returns the sorted names of the registered adapter backends.
+func AdapterBackendNames() []string {
+	names := make([]string, 0, len(adapterBackends))
+	for name := range adapterBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// agentgatewayBackend runs agentgateway with a LocalConfig file.
+type agentgatewayBackend struct{}
+
+func (agentgatewayBackend) Name() string {
+	return AgentgatewayBackendName
+}
+
+func (agentgatewayBackend) Image(defaults Defaults) string {
+	return defaults.adapterImage(defaults.AdapterVersion)
+}
+
+func (agentgatewayBackend) SupportsCopyBinary() bool {
+	return true
+}
+
+func (agentgatewayBackend) CopyArgs(binDir string) []string {
+	return []string{
+		"--copy-self",
+		binDir + "/agentgateway",
+	}
+}
+
+func (agentgatewayBackend) Command(
+	binDir string,
+	_ *v1alpha1.MCPServer,
+	_ *StdioTargetSpec,
+) ([]string, []string) {
+	binary := "agentgateway"
+	if binDir != "" {
+		binary = binDir + "/agentgateway"
+	}
+	return []string{binary}, []string{"-f", "/config/local.yaml"}
+}
+
+func (agentgatewayBackend) RenderConfig(
+	server *v1alpha1.MCPServer,
+	target *StdioTargetSpec,
+) (map[string]string, error) {
+	config, err := translateLocalConfig(server, MCPTarget{
+		Name:  server.Name,
+		Stdio: target,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	configYaml, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal MCP server config to YAML: %w", err)
+	}
+
+	return map[string]string{
+		"local.yaml": string(configYaml),
+	}, nil
+}
+
+func (agentgatewayBackend) AppProtocol(defaults Defaults) *string {
+	return defaults.appProtocol()
+}
+
+func (agentgatewayBackend) ReadinessProbe(*v1alpha1.MCPServer) corev1.ProbeHandler {
+	return corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path: "/healthz/ready",
+			Port: intstr.FromInt32(agentgatewayReadinessPort),
+		},
+	}
+}
+
+// mcpProxyBackend runs sparfenyuk/mcp-proxy, which is configured entirely through its
+// command line. The adapter is a Python application and cannot be copied into the MCP
+// server container, so it only supports the Sidecar adapter mode.
+type mcpProxyBackend struct{}
+
+func (mcpProxyBackend) Name() string {
+	return MCPProxyBackendName
+}
+
+func (mcpProxyBackend) Image(Defaults) string {
+	return mcpProxyImage
+}
+
+func (mcpProxyBackend) SupportsCopyBinary() bool {
+	return false
+}
+
+func (mcpProxyBackend) CopyArgs(string) []string {
+	return nil
+}
+
+func (mcpProxyBackend) Command(
+	_ string,
+	server *v1alpha1.MCPServer,
+	target *StdioTargetSpec,
+) ([]string, []string) {
+	args := []string{
+		"--host=0.0.0.0",
+		fmt.Sprintf("--port=%d", server.Spec.Deployment.Port),
+		// The stdio process inherits the environment of the adapter container, so that
+		// values from Secrets never appear in the arguments
+		"--pass-environment",
+		"--",
+		target.Cmd,
+	}
+	args = append(args, target.Args...)
+	return []string{"mcp-proxy"}, args
+}
+
+func (mcpProxyBackend) RenderConfig(server *v1alpha1.MCPServer, _ *StdioTargetSpec) (map[string]string, error) {
+	if serverMetrics(server) != nil {
+		return nil, fmt.Errorf("adapter backend %s does not support metrics", MCPProxyBackendName)
+	}
+	// mcp-proxy is configured through its command line
+	return map[string]string{}, nil
+}
+
+func (mcpProxyBackend) AppProtocol(defaults Defaults) *string {
+	return defaults.appProtocol()
+}
+
+func (mcpProxyBackend) ReadinessProbe(server *v1alpha1.MCPServer) corev1.ProbeHandler {
+	// mcp-proxy has no dedicated health endpoint, it is ready once it listens
+	return tcpProbeHandler(int32(server.Spec.Deployment.Port))
+}
+
+// ValidateVersion validates that a version string contains only allowed characters
+// to prevent potential image injection attacks
+func ValidateVersion(version string) error {
+	if !versionRegex.MatchString(version) {
+		return fmt.Errorf("invalid version format: %s (only alphanumeric characters, dots, and hyphens are allowed)", version)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package transportadapter
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestAdapterBackendGolden(t *testing.T) {
+	tests := []struct {
+		name           string
+		golden         string
+		defaultBackend string
+		server         func() *v1alpha1.MCPServer
+	}{
+		{
+			name:   "agentgateway copy binary",
+			golden: "agentgateway-copybinary.yaml",
+			server: func() *v1alpha1.MCPServer {
+				return newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+			},
+		},
+		{
+			name:   "agentgateway sidecar",
+			golden: "agentgateway-sidecar.yaml",
+			server: func() *v1alpha1.MCPServer {
+				return newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+			},
+		},
+		{
+			name:   "mcp-proxy selected by the MCPServer",
+			golden: "mcp-proxy-sidecar.yaml",
+			server: func() *v1alpha1.MCPServer {
+				server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+				server.Spec.StdioTransport.AdapterBackend = MCPProxyBackendName
+				return server
+			},
+		},
+		{
+			name:           "mcp-proxy selected by the controller default",
+			golden:         "mcp-proxy-sidecar.yaml",
+			defaultBackend: MCPProxyBackendName,
+			server: func() *v1alpha1.MCPServer {
+				return newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := NewTransportAdapterTranslator(
+				newTestScheme(t),
+				nil,
+				WithDefaultAdapterBackend(tt.defaultBackend),
+			)
+			outputs, err := translator.TranslateTransportAdapterOutputs(context.Background(), tt.server())
+			if err != nil {
+				t.Fatalf("failed to translate outputs: %v", err)
+			}
+
+			docs := make([]string, 0, len(outputs))
+			for _, output := range outputs {
+				doc, err := yaml.Marshal(output)
+				if err != nil {
+					t.Fatalf("failed to marshal %T: %v", output, err)
+				}
+				docs = append(docs, string(doc))
+			}
+			got := strings.Join(docs, "---\n")
+
+			path := filepath.Join("testdata", tt.golden)
+			if *updateGolden {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("outputs do not match %s, run the tests with -update to regenerate it\n%s", path, got)
+			}
+		})
+	}
+}
+
+func TestMCPProxyBackendRequiresSidecarMode(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Spec.StdioTransport.AdapterBackend = MCPProxyBackendName
+
+	translator := NewTransportAdapterTranslator(newTestScheme(t), nil)
+	if _, err := translator.TranslateTransportAdapterOutputs(context.Background(), server); err == nil {
+		t.Fatalf("expected an error for mcp-proxy in CopyBinary mode")
+	}
+}
+
+func TestMCPProxyBackendEnvNotInArgs(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.StdioTransport.AdapterBackend = MCPProxyBackendName
+	server.Spec.Deployment.Env = map[string]string{"API_TOKEN": "s3cr3t"}
+
+	containers := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec.Containers
+	adapter := findContainer(containers, "transport-adapter")
+	for _, arg := range append(adapter.Command, adapter.Args...) {
+		if strings.Contains(arg, "s3cr3t") || arg == "--env" {
+			t.Fatalf("expected the environment not to be passed as arguments, got %v %v", adapter.Command, adapter.Args)
+		}
+	}
+	if value := envValue(findContainer(containers, "mcp-server"), "API_TOKEN"); value != "s3cr3t" {
+		t.Errorf("expected the environment on the MCP server container, got %q", value)
+	}
+}
+
+func TestUnknownAdapterBackend(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+
+	translator := NewTransportAdapterTranslator(newTestScheme(t), nil, WithDefaultAdapterBackend("socat"))
+	if _, err := translator.TranslateTransportAdapterOutputs(context.Background(), server); err == nil {
+		t.Fatalf("expected an error for an unknown adapter backend")
+	}
+}
+
+func TestControllerDefaults(t *testing.T) {
+	defaults := BuiltinDefaults()
+	defaults.AdapterRepository = "registry.example.com/agentgateway"
+	defaults.AdapterVersion = "1.2.3"
+	defaults.Runners = MergeRunners(defaults.Runners, []Runner{{Name: "npx", Image: "registry.example.com/node:24"}})
+	defaults.KgatewayAppProtocol = false
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Spec.Deployment.Image = ""
+	server.Spec.Deployment.Cmd = "npx"
+	outputs := translateOutputs(t, server, WithDefaults(defaults))
+
+	spec := findDeployment(t, outputs).Spec.Template.Spec
+	if image := spec.Containers[0].Image; image != "registry.example.com/node:24" {
+		t.Errorf("expected the configured npx image, got %s", image)
+	}
+	if image := spec.InitContainers[0].Image; image != "registry.example.com/agentgateway:1.2.3-musl" {
+		t.Errorf("expected the configured adapter image, got %s", image)
+	}
+	if appProtocol := findService(t, outputs).Spec.Ports[0].AppProtocol; appProtocol != nil {
+		t.Errorf("expected no appProtocol with the kgateway app protocol disabled, got %s", *appProtocol)
+	}
+
+	defaults.AdapterVersion = "1.2.3; rm -rf /"
+	if err := defaults.Validate(); err == nil {
+		t.Errorf("expected an invalid adapter version to be rejected")
+	}
+}
@@ -0,0 +1,124 @@
+package transportadapter
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+const (
+	// bindingType is the Service Binding type of MCP servers.
+	bindingType = "mcp"
+
+	streamableHTTPPath = "/mcp"
+	ssePath            = "/sse"
+
+	streamableHTTPTransport = "streamable-http"
+	sseTransport            = "sse"
+)
+
+// ServerEndpoints returns the addresses of the MCP endpoints served by the Service of
+// the MCPServer. The transport adapter of stdio servers serves both endpoints, while
+// HTTP servers only serve the endpoint at the path of their HTTP transport.
+func ServerEndpoints(server *v1alpha1.MCPServer) v1alpha1.MCPServerEndpoints {
+	base := fmt.Sprintf("http://%s:%d", serverHost(server), server.Spec.Deployment.Port)
+	if server.Spec.TransportType != v1alpha1.TransportTypeHTTP {
+		return v1alpha1.MCPServerEndpoints{
+			StreamableHTTP: base + streamableHTTPPath,
+			SSE:            base + ssePath,
+		}
+	}
+	path, transport := httpEndpoint(server)
+	if transport == sseTransport {
+		return v1alpha1.MCPServerEndpoints{SSE: base + path}
+	}
+	return v1alpha1.MCPServerEndpoints{StreamableHTTP: base + path}
+}
+
+// ServerURL returns the address of the main MCP endpoint of the MCPServer, the Streamable
+// HTTP endpoint unless the server only serves HTTP+SSE.
+func ServerURL(endpoints v1alpha1.MCPServerEndpoints) string {
+	if endpoints.StreamableHTTP != "" {
+		return endpoints.StreamableHTTP
+	}
+	return endpoints.SSE
+}
+
+// httpEndpoint returns the path and the transport of the endpoint of an HTTP server.
+// Paths ending with /sse are served over HTTP+SSE, other paths over Streamable HTTP.
+func httpEndpoint(server *v1alpha1.MCPServer) (string, string) {
+	path := ""
+	if server.Spec.HTTPTransport != nil {
+		path = server.Spec.HTTPTransport.TargetPath
+	}
+	if path == "" {
+		return streamableHTTPPath, streamableHTTPTransport
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if strings.HasSuffix(strings.TrimSuffix(path, "/"), ssePath) {
+		return path, sseTransport
+	}
+	return path, streamableHTTPTransport
+}
+
+// BindingSecretName returns the name of the Secret holding the connection information
+// of the MCPServer.
+func BindingSecretName(server *v1alpha1.MCPServer) string {
+	return server.Name + "-binding"
+}
+
+func serverHost(server *v1alpha1.MCPServer) string {
+	return fmt.Sprintf("%s.%s.svc", server.Name, server.Namespace)
+}
+
+// translateBindingSecret translates the connection information of the MCPServer into a
+// Secret following the Service Binding specification. MCPServers do not authenticate
+// their clients, so the Secret holds no credentials.
+func (t *transportAdapterTranslator) translateBindingSecret(server *v1alpha1.MCPServer) (*corev1.Secret, error) {
+	labels := maps.Clone(selectorLabels(server))
+	labels[v1alpha1.MCPServerBindingLabel] = "true"
+
+	endpoints := ServerEndpoints(server)
+	url := ServerURL(endpoints)
+	path, transport := streamableHTTPPath, streamableHTTPTransport
+	if server.Spec.TransportType == v1alpha1.TransportTypeHTTP {
+		path, transport = httpEndpoint(server)
+	}
+	data := map[string][]byte{
+		"type":      []byte(bindingType),
+		"provider":  []byte("kmcp"),
+		"host":      []byte(serverHost(server)),
+		"port":      []byte(strconv.Itoa(int(server.Spec.Deployment.Port))),
+		"path":      []byte(path),
+		"url":       []byte(url),
+		"transport": []byte(transport),
+	}
+	// Servers serving both endpoints also publish the HTTP+SSE endpoint for older clients
+	if endpoints.StreamableHTTP != "" && endpoints.SSE != "" {
+		data["sse-url"] = []byte(endpoints.SSE)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BindingSecretName(server),
+			Namespace: server.Namespace,
+			Labels:    labels,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		Type: corev1.SecretType("servicebinding.io/" + bindingType),
+		Data: data,
+	}
+
+	return secret, controllerutil.SetOwnerReference(server, secret, t.scheme)
+}
@@ -0,0 +1,77 @@
+package transportadapter
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func findBindingSecret(t *testing.T, outputs []client.Object) *corev1.Secret {
+	t.Helper()
+	for _, output := range outputs {
+		if secret, ok := output.(*corev1.Secret); ok && secret.Name == "test-server-binding" {
+			return secret
+		}
+	}
+	t.Fatalf("no binding secret found in outputs")
+	return nil
+}
+
+func TestServerEndpoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport *v1alpha1.HTTPTransport
+		want      v1alpha1.MCPServerEndpoints
+	}{
+		{
+			name:      "streamable HTTP at the default path",
+			transport: &v1alpha1.HTTPTransport{TargetPort: 3000},
+			want:      v1alpha1.MCPServerEndpoints{StreamableHTTP: "http://test-server.default.svc:3000/mcp"},
+		},
+		{
+			name:      "streamable HTTP at a custom path",
+			transport: &v1alpha1.HTTPTransport{TargetPort: 3000, TargetPath: "/api/mcp"},
+			want:      v1alpha1.MCPServerEndpoints{StreamableHTTP: "http://test-server.default.svc:3000/api/mcp"},
+		},
+		{
+			name:      "HTTP+SSE",
+			transport: &v1alpha1.HTTPTransport{TargetPort: 3000, TargetPath: "/sse"},
+			want:      v1alpha1.MCPServerEndpoints{SSE: "http://test-server.default.svc:3000/sse"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+			server.Spec.TransportType = v1alpha1.TransportTypeHTTP
+			server.Spec.HTTPTransport = tt.transport
+			if got := ServerEndpoints(server); got != tt.want {
+				t.Errorf("expected endpoints %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	endpoints := ServerEndpoints(server)
+	if endpoints.StreamableHTTP == "" || endpoints.SSE == "" {
+		t.Errorf("expected stdio servers to serve both endpoints, got %+v", endpoints)
+	}
+}
+
+func TestBindingSecretSSEOnly(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Spec.TransportType = v1alpha1.TransportTypeHTTP
+	server.Spec.HTTPTransport = &v1alpha1.HTTPTransport{TargetPort: 3000, TargetPath: "/sse"}
+
+	secret := findBindingSecret(t, translateOutputs(t, server))
+	if string(secret.Data["url"]) != "http://test-server.default.svc:3000/sse" ||
+		string(secret.Data["path"]) != "/sse" ||
+		string(secret.Data["transport"]) != "sse" {
+		t.Errorf("expected the binding to point at the HTTP+SSE endpoint, got %v", secret.Data)
+	}
+	if _, ok := secret.Data["sse-url"]; ok {
+		t.Errorf("expected no separate sse-url for a server serving a single endpoint")
+	}
+}
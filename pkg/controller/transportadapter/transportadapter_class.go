@@ -0,0 +1,147 @@
+package transportadapter
+
+import (
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// IsDefaultServerClass reports whether the MCPServerClass is annotated as the default class.
+func IsDefaultServerClass(class *v1alpha1.MCPServerClass) bool {
+	return class.Annotations[v1alpha1.MCPServerClassDefaultAnnotation] == "true"
+}
+
+// ApplyServerClass returns a copy of the MCPServer with the defaults of its MCPServerClass
+// merged in, and status.className set to the class. Settings made on the MCPServer take
+// precedence over the class. It returns an error when the MCPServer violates the policy
+// of the class. defaultBackend is the adapter backend of the controller, which decides
+// whether the adapter version of the class applies, and defaults the repository of the
+// adapter image.
+func ApplyServerClass(
+	server *v1alpha1.MCPServer,
+	class *v1alpha1.MCPServerClass,
+	defaultBackend string,
+	defaults Defaults,
+) (*v1alpha1.MCPServer, error) {
+	merged := server.DeepCopy()
+	if class == nil {
+		merged.Status.ClassName = ""
+		return merged, nil
+	}
+	merged.Status.ClassName = class.Name
+
+	if len(class.Spec.AllowedTransports) > 0 &&
+		!slices.Contains(class.Spec.AllowedTransports, server.Spec.TransportType) {
+		return nil, fmt.Errorf("transport type %s is not allowed by MCPServerClass %s, must be one of %v",
+			server.Spec.TransportType, class.Name, class.Spec.AllowedTransports)
+	}
+	if merged.Spec.SecurityProfile == "" {
+		merged.Spec.SecurityProfile = class.Spec.SecurityProfile
+	}
+	if class.Spec.Deployment != nil {
+		mergeClassDeployment(&merged.Spec.Deployment, class.Spec.Deployment)
+	}
+
+	if version := class.Spec.AdapterVersion; version != "" && server.Spec.TransportType == v1alpha1.TransportTypeStdio {
+		if err := ValidateVersion(version); err != nil {
+			return nil, fmt.Errorf("invalid adapterVersion of MCPServerClass %s: %w", class.Name, err)
+		}
+		backendName := defaultBackend
+		if merged.Spec.StdioTransport != nil && merged.Spec.StdioTransport.AdapterBackend != "" {
+			backendName = merged.Spec.StdioTransport.AdapterBackend
+		}
+		if backendName == "" || backendName == AgentgatewayBackendName {
+			if merged.Spec.Deployment.InitContainer == nil {
+				merged.Spec.Deployment.InitContainer = &v1alpha1.InitContainerConfig{}
+			}
+			if merged.Spec.Deployment.InitContainer.Image == "" {
+				merged.Spec.Deployment.InitContainer.Image = defaults.adapterImage(version)
+			}
+		}
+	}
+	return merged, nil
+}
+
+// mergeClassDeployment fills the deployment settings that are not set on the MCPServer
+// with the settings of the class.
+func mergeClassDeployment(deployment *v1alpha1.MCPServerDeployment, defaults *v1alpha1.MCPServerClassDeployment) {
+	defaults = defaults.DeepCopy()
+
+	if deployment.ImagePullPolicy == "" {
+		deployment.ImagePullPolicy = defaults.ImagePullPolicy
+	}
+	if len(deployment.ImagePullSecrets) == 0 {
+		deployment.ImagePullSecrets = defaults.ImagePullSecrets
+	}
+	if deployment.Resources == nil {
+		deployment.Resources = defaults.Resources
+	}
+	if deployment.SecurityContext == nil {
+		deployment.SecurityContext = defaults.SecurityContext
+	}
+	if deployment.PodSecurityContext == nil {
+		deployment.PodSecurityContext = defaults.PodSecurityContext
+	}
+	if deployment.Probes == nil {
+		deployment.Probes = defaults.Probes
+	}
+	if len(deployment.Tolerations) == 0 {
+		deployment.Tolerations = defaults.Tolerations
+	}
+	if deployment.Affinity == nil {
+		deployment.Affinity = defaults.Affinity
+	}
+	deployment.Labels = mergeStringMaps(defaults.Labels, deployment.Labels)
+	deployment.Annotations = mergeStringMaps(defaults.Annotations, deployment.Annotations)
+	deployment.NodeSelector = mergeStringMaps(defaults.NodeSelector, deployment.NodeSelector)
+
+	if initContainer := defaults.InitContainer; initContainer != nil {
+		if deployment.InitContainer == nil {
+			deployment.InitContainer = &v1alpha1.InitContainerConfig{}
+		}
+		if deployment.InitContainer.Image == "" {
+			deployment.InitContainer.Image = initContainer.Image
+		}
+		if deployment.InitContainer.ImagePullPolicy == "" {
+			deployment.InitContainer.ImagePullPolicy = initContainer.ImagePullPolicy
+		}
+		if deployment.InitContainer.Resources == nil {
+			deployment.InitContainer.Resources = initContainer.Resources
+		}
+		if deployment.InitContainer.SecurityContext == nil {
+			deployment.InitContainer.SecurityContext = initContainer.SecurityContext
+		}
+	}
+
+	// Variables of the class are added before those of the MCPServer, unless the
+	// MCPServer defines a variable of the same name in env or envVars
+	var envVars []corev1.EnvVar
+	for _, envVar := range defaults.EnvVars {
+		if _, ok := deployment.Env[envVar.Name]; ok {
+			continue
+		}
+		if slices.ContainsFunc(deployment.EnvVars, func(e corev1.EnvVar) bool { return e.Name == envVar.Name }) {
+			continue
+		}
+		envVars = append(envVars, envVar)
+	}
+	deployment.EnvVars = append(envVars, deployment.EnvVars...)
+}
+
+// mergeStringMaps returns the union of the maps, the values of overrides taking precedence.
+func mergeStringMaps(defaults, overrides map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return overrides
+	}
+	merged := make(map[string]string, len(defaults)+len(overrides))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
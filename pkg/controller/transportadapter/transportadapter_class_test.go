@@ -0,0 +1,103 @@
+package transportadapter
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func newTestClass() *v1alpha1.MCPServerClass {
+	return &v1alpha1.MCPServerClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "standard"},
+		Spec: v1alpha1.MCPServerClassSpec{
+			Deployment: &v1alpha1.MCPServerClassDeployment{
+				ImagePullPolicy:  corev1.PullAlways,
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+				EnvVars: []corev1.EnvVar{
+					{Name: "FOO", Value: "class"},
+					{Name: "LOG_LEVEL", Value: "info"},
+				},
+				Labels:       map[string]string{"team": "platform", "tier": "mcp"},
+				NodeSelector: map[string]string{"pool": "mcp"},
+				Tolerations:  []corev1.Toleration{{Key: "mcp", Operator: corev1.TolerationOpExists}},
+			},
+			SecurityProfile: v1alpha1.SecurityProfileRestricted,
+			AdapterVersion:  "1.0.0",
+		},
+	}
+}
+
+func TestApplyServerClass(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Spec.Deployment.Labels = map[string]string{"team": "search"}
+
+	merged, err := ApplyServerClass(server, newTestClass(), "", BuiltinDefaults())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deployment := merged.Spec.Deployment
+	if merged.Status.ClassName != "standard" {
+		t.Errorf("expected the class name in the status, got %q", merged.Status.ClassName)
+	}
+	if deployment.ImagePullPolicy != corev1.PullAlways || len(deployment.ImagePullSecrets) != 1 ||
+		len(deployment.Tolerations) != 1 || deployment.NodeSelector["pool"] != "mcp" {
+		t.Errorf("expected the class defaults, got %+v", deployment)
+	}
+	if deployment.Labels["team"] != "search" || deployment.Labels["tier"] != "mcp" {
+		t.Errorf("expected the labels to be merged with the server taking precedence, got %v", deployment.Labels)
+	}
+	// FOO is set in the env map of the server
+	if len(deployment.EnvVars) != 1 || deployment.EnvVars[0].Name != "LOG_LEVEL" {
+		t.Errorf("expected only the class variables not set on the server, got %+v", deployment.EnvVars)
+	}
+	if merged.Spec.SecurityProfile != v1alpha1.SecurityProfileRestricted {
+		t.Errorf("expected the security profile of the class, got %q", merged.Spec.SecurityProfile)
+	}
+	if deployment.InitContainer == nil || !strings.HasSuffix(deployment.InitContainer.Image, ":1.0.0-musl") {
+		t.Errorf("expected the adapter version of the class, got %+v", deployment.InitContainer)
+	}
+	if server.Spec.Deployment.ImagePullPolicy != "" || server.Spec.Deployment.Labels["tier"] != "" {
+		t.Errorf("expected the MCPServer not to be modified")
+	}
+}
+
+func TestApplyServerClassAdapterVersion(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.StdioTransport.AdapterBackend = MCPProxyBackendName
+
+	merged, err := ApplyServerClass(server, newTestClass(), "", BuiltinDefaults())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if merged.Spec.Deployment.InitContainer != nil {
+		t.Errorf("expected the agentgateway adapter version not to apply to mcp-proxy, got %+v",
+			merged.Spec.Deployment.InitContainer)
+	}
+}
+
+func TestApplyServerClassAllowedTransports(t *testing.T) {
+	class := newTestClass()
+	class.Spec.AllowedTransports = []v1alpha1.TransportType{v1alpha1.TransportTypeHTTP}
+
+	_, err := ApplyServerClass(newStdioServer(v1alpha1.StdioAdapterModeCopyBinary), class, "", BuiltinDefaults())
+	if err == nil || !strings.Contains(err.Error(), "transport type stdio is not allowed") {
+		t.Errorf("expected the stdio transport to be rejected, got %v", err)
+	}
+}
+
+func TestApplyServerClassNone(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Status.ClassName = "removed"
+
+	merged, err := ApplyServerClass(server, nil, "", BuiltinDefaults())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if merged.Status.ClassName != "" {
+		t.Errorf("expected the class name to be cleared, got %q", merged.Status.ClassName)
+	}
+}
@@ -0,0 +1,16 @@
+package transportadapter
+
+// RawConfig is the process-level section of the agentgateway LocalConfig.
+type RawConfig struct {
+	Tracing *TracingConfig `json:"tracing,omitempty" yaml:"tracing,omitempty"`
+	// StatsAddr is the address agentgateway serves its Prometheus metrics on.
+	StatsAddr string `json:"statsAddr,omitempty" yaml:"statsAddr,omitempty"`
+}
+
+// TracingConfig configures the OpenTelemetry traces exported by agentgateway.
+type TracingConfig struct {
+	OTLPEndpoint string `json:"otlpEndpoint" yaml:"otlpEndpoint"`
+	// RandomSampling is the ratio of new traces that are sampled, between 0 and 1.
+	RandomSampling     string            `json:"randomSampling,omitempty" yaml:"randomSampling,omitempty"`
+	ResourceAttributes map[string]string `json:"resourceAttributes,omitempty" yaml:"resourceAttributes,omitempty"`
+}
@@ -0,0 +1,80 @@
+package transportadapter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Defaults are the images and features of the translation configured for the whole
+// controller. The controllers take them from the current controller configuration on
+// every reconcile, so that changes of its file apply without a restart.
+type Defaults struct {
+	// AdapterRepository is the repository of the agentgateway transport adapter image.
+	AdapterRepository string
+	// AdapterVersion is the version of the agentgateway transport adapter image.
+	AdapterVersion string
+	// Runners are the commands running MCP server packages, such as npx and uvx.
+	Runners []Runner
+	// KgatewayAppProtocol sets the appProtocol that lets kgateway route to the Services
+	// of MCP servers and gateways as MCP backends.
+	KgatewayAppProtocol bool
+}
+
+// BuiltinDefaults returns the defaults used when the controller configuration does not
+// set them.
+func BuiltinDefaults() Defaults {
+	return Defaults{
+		AdapterRepository:   transportAdapterRepository,
+		AdapterVersion:      defaultTransportAdapterVersion,
+		Runners:             BuiltinRunners(),
+		KgatewayAppProtocol: true,
+	}
+}
+
+// Validate checks the defaults. The version of the adapter is validated, since it ends
+// up in an image reference, and so are the runners.
+func (d Defaults) Validate() error {
+	if err := ValidateVersion(d.AdapterVersion); err != nil {
+		return fmt.Errorf("invalid transport adapter version: %w", err)
+	}
+	for _, runner := range d.Runners {
+		if err := CheckRunner(runner); err != nil {
+			return fmt.Errorf("invalid runner %q: %w", runner.Name, err)
+		}
+	}
+	return nil
+}
+
+// LookupRunner returns the registered runner of the command.
+func (d Defaults) LookupRunner(cmd string) (Runner, bool) {
+	for _, runner := range d.Runners {
+		if runner.Name == cmd {
+			return runner, true
+		}
+	}
+	return Runner{}, false
+}
+
+// RunnerNames returns the sorted names of the registered runners.
+func (d Defaults) RunnerNames() []string {
+	names := make([]string, 0, len(d.Runners))
+	for _, runner := range d.Runners {
+		names = append(names, runner.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// adapterImage returns the agentgateway transport adapter image of the given version.
+func (d Defaults) adapterImage(version string) string {
+	return fmt.Sprintf("%s:%s-musl", d.AdapterRepository, version)
+}
+
+// appProtocol returns the appProtocol that lets kgateway route to a Service as an MCP
+// backend, unless it was disabled in the controller configuration.
+func (d Defaults) appProtocol() *string {
+	if !d.KgatewayAppProtocol {
+		return nil
+	}
+	return makePtr(kgatewayMcpAppProtocol)
+}
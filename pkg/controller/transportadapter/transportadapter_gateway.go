@@ -0,0 +1,443 @@
+package transportadapter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+const jwksMountPath = "/jwks"
+
+// JWTAuthPolicy is the agentgateway jwtAuth route policy.
+type JWTAuthPolicy struct {
+	Mode      string     `json:"mode" yaml:"mode"`
+	Issuer    string     `json:"issuer" yaml:"issuer"`
+	Audiences []string   `json:"audiences,omitempty" yaml:"audiences,omitempty"`
+	JWKS      JWKSSource `json:"jwks" yaml:"jwks"`
+}
+
+// JWKSSource is the location of a JSON Web Key Set.
+type JWKSSource struct {
+	URL  string `json:"url,omitempty" yaml:"url,omitempty"`
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+// LocalRateLimitPolicy is an agentgateway localRateLimit route policy.
+type LocalRateLimitPolicy struct {
+	MaxTokens     uint64 `json:"maxTokens" yaml:"maxTokens"`
+	TokensPerFill uint64 `json:"tokensPerFill" yaml:"tokensPerFill"`
+	FillInterval  string `json:"fillInterval" yaml:"fillInterval"`
+	Type          string `json:"type" yaml:"type"`
+}
+
+// GatewayTranslator is the interface for translating MCPGateway objects to the
+// objects of a shared gateway.
+type GatewayTranslator interface {
+	TranslateGatewayOutputs(
+		ctx context.Context,
+		gateway *v1alpha1.MCPGateway,
+		servers []v1alpha1.MCPServer,
+	) ([]client.Object, error)
+}
+
+type gatewayTranslator struct {
+	scheme   *runtime.Scheme
+	defaults Defaults
+}
+
+// NewGatewayTranslator returns a translator of MCPGateways using the images and features
+// of the controller defaults.
+func NewGatewayTranslator(scheme *runtime.Scheme, defaults Defaults) GatewayTranslator {
+	return &gatewayTranslator{
+		scheme:   scheme,
+		defaults: defaults,
+	}
+}
+
+func (t *gatewayTranslator) TranslateGatewayOutputs(
+	_ context.Context,
+	gateway *v1alpha1.MCPGateway,
+	servers []v1alpha1.MCPServer,
+) ([]client.Object, error) {
+	configMap, err := t.translateGatewayConfigMap(gateway, servers)
+	if err != nil {
+		return nil, err
+	}
+	deployment, err := t.translateGatewayDeployment(gateway, configMap.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate gateway deployment: %w", err)
+	}
+	service, err := t.translateGatewayService(gateway)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate gateway service: %w", err)
+	}
+
+	return []client.Object{
+		deployment,
+		service,
+		configMap,
+	}, nil
+}
+
+func (t *gatewayTranslator) translateGatewayDeployment(
+	gateway *v1alpha1.MCPGateway,
+	configData map[string]string,
+) (*appsv1.Deployment, error) {
+	image := gateway.Spec.Deployment.Image
+	if image == "" {
+		image = t.defaults.adapterImage(t.defaults.AdapterVersion)
+	}
+	pullPolicy := gateway.Spec.Deployment.ImagePullPolicy
+	if pullPolicy == "" {
+		pullPolicy = corev1.PullIfNotPresent
+	}
+	var resources corev1.ResourceRequirements
+	if gateway.Spec.Deployment.Resources != nil {
+		resources = *gateway.Spec.Deployment.Resources
+	}
+
+	volumeMounts := []corev1.VolumeMount{{
+		Name:      "config",
+		MountPath: "/config",
+	}}
+	volumes := []corev1.Volume{{
+		Name: "config",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: gateway.Name, // ConfigMap name matches the MCPGateway name
+				},
+			},
+		},
+	}}
+	if jwt := gatewayJWT(gateway); jwt != nil && jwt.JWKSConfigMapRef != nil {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "jwks",
+			MountPath: jwksMountPath,
+			ReadOnly:  true,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: "jwks",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: jwt.JWKSConfigMapRef.LocalObjectReference,
+					Items: []corev1.KeyToPath{{
+						Key:  jwt.JWKSConfigMapRef.Key,
+						Path: jwt.JWKSConfigMapRef.Key,
+					}},
+				},
+			},
+		})
+	}
+
+	podLabels := gatewaySelectorLabels(gateway)
+	podLabels["app.kubernetes.io/component"] = "mcp-gateway"
+	podLabels["app.kubernetes.io/managed-by"] = "kmcp"
+
+	podTemplate := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: podLabels,
+		},
+		Spec: corev1.PodSpec{
+			NodeSelector: gateway.Spec.Deployment.NodeSelector,
+			Tolerations:  gateway.Spec.Deployment.Tolerations,
+			Containers: []corev1.Container{{
+				Name:            "gateway",
+				Image:           image,
+				ImagePullPolicy: pullPolicy,
+				Args: []string{
+					"-f",
+					"/config/local.yaml",
+				},
+				Ports: []corev1.ContainerPort{{
+					Name:          "http",
+					ContainerPort: int32(gatewayPort(gateway)),
+					Protocol:      corev1.ProtocolTCP,
+				}},
+				Resources:       resources,
+				VolumeMounts:    volumeMounts,
+				SecurityContext: gateway.Spec.Deployment.SecurityContext,
+			}},
+			Volumes: volumes,
+		},
+	}
+	// Restart the gateway when its routes or policies change
+	addMCPServerConfigHashAnnotation(&podTemplate, configData)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gateway.Name,
+			Namespace: gateway.Namespace,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: gateway.Spec.Deployment.Replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: gatewaySelectorLabels(gateway),
+			},
+			Template: podTemplate,
+		},
+	}
+
+	return deployment, controllerutil.SetOwnerReference(gateway, deployment, t.scheme)
+}
+
+func (t *gatewayTranslator) translateGatewayService(
+	gateway *v1alpha1.MCPGateway,
+) (*corev1.Service, error) {
+	port := gatewayPort(gateway)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gateway.Name,
+			Namespace: gateway.Namespace,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:     "http",
+				Protocol: "TCP",
+				Port:     int32(port),
+				TargetPort: intstr.IntOrString{
+					IntVal: int32(port),
+				},
+				AppProtocol: t.defaults.appProtocol(),
+			}},
+			Selector: gatewaySelectorLabels(gateway),
+		},
+	}
+
+	return service, controllerutil.SetOwnerReference(gateway, service, t.scheme)
+}
+
+func (t *gatewayTranslator) translateGatewayConfigMap(
+	gateway *v1alpha1.MCPGateway,
+	servers []v1alpha1.MCPServer,
+) (*corev1.ConfigMap, error) {
+	config, err := translateGatewayConfig(gateway, servers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate gateway config: %w", err)
+	}
+	configYaml, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal gateway config to YAML: %w", err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gateway.Name,
+			Namespace: gateway.Namespace,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		Data: map[string]string{
+			"local.yaml": string(configYaml),
+		},
+	}
+
+	return configMap, controllerutil.SetOwnerReference(gateway, configMap, t.scheme)
+}
+
+// translateGatewayConfig builds the agentgateway config that routes to the Services of
+// the given MCPServers, either on a path per server or federated behind a single endpoint.
+func translateGatewayConfig(gateway *v1alpha1.MCPGateway, servers []v1alpha1.MCPServer) (*LocalConfig, error) {
+	sorted := make([]v1alpha1.MCPServer, len(servers))
+	copy(sorted, servers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	targets := make([]MCPTarget, 0, len(sorted))
+	for i := range sorted {
+		target, err := gatewayServerTarget(&sorted[i])
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	var routes []LocalRoute
+	switch gateway.Spec.RoutingMode {
+	case v1alpha1.MCPGatewayRoutingModeFederated:
+		if len(targets) > 0 {
+			policies, err := translateGatewayPolicies(gateway)
+			if err != nil {
+				return nil, err
+			}
+			routes = append(routes, LocalRoute{
+				RouteName: "mcp",
+				Matches:   mcpRouteMatches(),
+				Policies:  policies,
+				Backends: []RouteBackend{{
+					Weight: 100,
+					MCP: &MCPBackend{
+						Targets: targets,
+					},
+				}},
+			})
+		}
+	case v1alpha1.MCPGatewayRoutingModePath, "":
+		for _, target := range targets {
+			policies, err := translateGatewayPolicies(gateway)
+			if err != nil {
+				return nil, err
+			}
+			if policies == nil {
+				policies = &FilterOrPolicy{}
+			}
+			// Strip the server prefix so that the backend sees /mcp and /sse
+			policies.URLRewrite = &URLRewrite{
+				Path: &PathRedirect{
+					Prefix: "/",
+				},
+			}
+			routes = append(routes, LocalRoute{
+				RouteName: target.Name,
+				Matches: []RouteMatch{{
+					Path: PathMatch{
+						PathPrefix: "/" + target.Name,
+					},
+				}},
+				Policies: policies,
+				Backends: []RouteBackend{{
+					Weight: 100,
+					MCP: &MCPBackend{
+						Targets: []MCPTarget{target},
+					},
+				}},
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported routing mode: %s", gateway.Spec.RoutingMode)
+	}
+
+	return &LocalConfig{
+		Config: struct{}{},
+		Binds: []LocalBind{{
+			Port: gatewayPort(gateway),
+			Listeners: []LocalListener{{
+				Name:     "default",
+				Protocol: LocalListenerProtocolHTTP,
+				Routes:   routes,
+			}},
+		}},
+	}, nil
+}
+
+// gatewayServerTarget returns the MCP target that reaches the Service of an MCPServer.
+func gatewayServerTarget(server *v1alpha1.MCPServer) (MCPTarget, error) {
+	port := server.Spec.Deployment.Port
+	if port == 0 {
+		return MCPTarget{}, fmt.Errorf("deployment port must be specified for MCPServer %s", server.Name)
+	}
+
+	path := "/sse"
+	if server.Spec.TransportType == v1alpha1.TransportTypeHTTP &&
+		server.Spec.HTTPTransport != nil && server.Spec.HTTPTransport.TargetPath != "" {
+		path = server.Spec.HTTPTransport.TargetPath
+	}
+
+	return MCPTarget{
+		Name: server.Name,
+		SSE: &SSETargetSpec{
+			Host: fmt.Sprintf("%s.%s.svc.cluster.local", server.Name, server.Namespace),
+			Port: uint32(port),
+			Path: path,
+		},
+	}, nil
+}
+
+// translateGatewayPolicies translates the authentication and rate limit policies of the
+// gateway, which apply to every route.
+func translateGatewayPolicies(gateway *v1alpha1.MCPGateway) (*FilterOrPolicy, error) {
+	var policies *FilterOrPolicy
+
+	if jwt := gatewayJWT(gateway); jwt != nil {
+		jwks := JWKSSource{URL: jwt.JWKSURL}
+		if jwt.JWKSConfigMapRef != nil {
+			jwks = JWKSSource{File: fmt.Sprintf("%s/%s", jwksMountPath, jwt.JWKSConfigMapRef.Key)}
+		}
+		if jwks.URL == "" && jwks.File == "" {
+			return nil, fmt.Errorf("jwt authentication requires jwksURL or jwksConfigMapRef")
+		}
+		policies = &FilterOrPolicy{}
+		policies.JWTAuth = &JWTAuthPolicy{
+			Mode:      "strict",
+			Issuer:    jwt.Issuer,
+			Audiences: jwt.Audiences,
+			JWKS:      jwks,
+		}
+	}
+
+	if rateLimit := gateway.Spec.RateLimit; rateLimit != nil {
+		if policies == nil {
+			policies = &FilterOrPolicy{}
+		}
+		policies.LocalRateLimit = []interface{}{
+			&LocalRateLimitPolicy{
+				MaxTokens:     rateLimit.MaxTokens,
+				TokensPerFill: rateLimit.TokensPerFill,
+				FillInterval:  rateLimit.FillInterval.Duration.String(),
+				Type:          "requests",
+			},
+		}
+	}
+
+	return policies, nil
+}
+
+// mcpRouteMatches returns the route matches of the MCP endpoints.
+func mcpRouteMatches() []RouteMatch {
+	return []RouteMatch{
+		{
+			Path: PathMatch{
+				PathPrefix: "/sse",
+			},
+		},
+		{
+			Path: PathMatch{
+				PathPrefix: "/mcp",
+			},
+		},
+	}
+}
+
+func gatewayJWT(gateway *v1alpha1.MCPGateway) *v1alpha1.JWTAuthentication {
+	if gateway.Spec.Authentication == nil {
+		return nil
+	}
+	return gateway.Spec.Authentication.JWT
+}
+
+func gatewayPort(gateway *v1alpha1.MCPGateway) uint16 {
+	if gateway.Spec.Deployment.Port == 0 {
+		return 3000
+	}
+	return gateway.Spec.Deployment.Port
+}
+
+// gatewaySelectorLabels returns the labels used to select the pods of the MCPGateway.
+func gatewaySelectorLabels(gateway *v1alpha1.MCPGateway) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":     gateway.Name,
+		"app.kubernetes.io/instance": gateway.Name,
+	}
+}
@@ -0,0 +1,132 @@
+package transportadapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func newGatewayServers() []v1alpha1.MCPServer {
+	return []v1alpha1.MCPServer{
+		*newStdioServer(v1alpha1.StdioAdapterModeCopyBinary),
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "http-server",
+				Namespace: "default",
+			},
+			Spec: v1alpha1.MCPServerSpec{
+				TransportType: v1alpha1.TransportTypeHTTP,
+				HTTPTransport: &v1alpha1.HTTPTransport{
+					TargetPort: 8080,
+					TargetPath: "/mcp",
+				},
+				Deployment: v1alpha1.MCPServerDeployment{
+					Image: "http-image:latest",
+					Port:  8080,
+				},
+			},
+		},
+	}
+}
+
+func newGateway(mode v1alpha1.MCPGatewayRoutingMode) *v1alpha1.MCPGateway {
+	return &v1alpha1.MCPGateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gateway",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.MCPGatewaySpec{
+			RoutingMode: mode,
+		},
+	}
+}
+
+func TestGatewayPathRouting(t *testing.T) {
+	config, err := translateGatewayConfig(newGateway(v1alpha1.MCPGatewayRoutingModePath), newGatewayServers())
+	if err != nil {
+		t.Fatalf("failed to translate gateway config: %v", err)
+	}
+
+	routes := config.Binds[0].Listeners[0].Routes
+	if len(routes) != 2 {
+		t.Fatalf("expected one route per server, got %d", len(routes))
+	}
+	// Routes are sorted by server name
+	if routes[0].RouteName != "http-server" || routes[1].RouteName != "test-server" {
+		t.Errorf("unexpected route order: %s, %s", routes[0].RouteName, routes[1].RouteName)
+	}
+	if routes[0].Matches[0].Path.PathPrefix != "/http-server" {
+		t.Errorf("expected a path prefix per server, got %s", routes[0].Matches[0].Path.PathPrefix)
+	}
+	if routes[0].Policies == nil || routes[0].Policies.URLRewrite == nil {
+		t.Errorf("expected the server prefix to be rewritten")
+	}
+
+	httpTarget := routes[0].Backends[0].MCP.Targets[0]
+	if httpTarget.SSE.Host != "http-server.default.svc.cluster.local" || httpTarget.SSE.Path != "/mcp" {
+		t.Errorf("unexpected http server target: %+v", httpTarget.SSE)
+	}
+	stdioTarget := routes[1].Backends[0].MCP.Targets[0]
+	if stdioTarget.SSE.Port != 3000 || stdioTarget.SSE.Path != "/sse" {
+		t.Errorf("unexpected stdio server target: %+v", stdioTarget.SSE)
+	}
+}
+
+func TestGatewayFederatedRouting(t *testing.T) {
+	gateway := newGateway(v1alpha1.MCPGatewayRoutingModeFederated)
+	gateway.Spec.Authentication = &v1alpha1.MCPGatewayAuthentication{
+		JWT: &v1alpha1.JWTAuthentication{
+			Issuer: "https://issuer.example.com",
+			JWKSConfigMapRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "jwks"},
+				Key:                  "keys.json",
+			},
+		},
+	}
+	gateway.Spec.RateLimit = &v1alpha1.MCPGatewayRateLimit{
+		MaxTokens:     10,
+		TokensPerFill: 1,
+		FillInterval:  metav1.Duration{Duration: time.Second},
+	}
+
+	config, err := translateGatewayConfig(gateway, newGatewayServers())
+	if err != nil {
+		t.Fatalf("failed to translate gateway config: %v", err)
+	}
+
+	routes := config.Binds[0].Listeners[0].Routes
+	if len(routes) != 1 {
+		t.Fatalf("expected a single federated route, got %d", len(routes))
+	}
+	if targets := routes[0].Backends[0].MCP.Targets; len(targets) != 2 {
+		t.Errorf("expected the route to federate both servers, got %d targets", len(targets))
+	}
+
+	jwt, ok := routes[0].Policies.JWTAuth.(*JWTAuthPolicy)
+	if !ok || jwt.JWKS.File != "/jwks/keys.json" {
+		t.Errorf("unexpected jwt policy: %+v", routes[0].Policies.JWTAuth)
+	}
+	rateLimit, ok := routes[0].Policies.LocalRateLimit[0].(*LocalRateLimitPolicy)
+	if !ok || rateLimit.FillInterval != "1s" {
+		t.Errorf("unexpected rate limit policy: %+v", routes[0].Policies.LocalRateLimit)
+	}
+
+	outputs, err := NewGatewayTranslator(newTestScheme(t), BuiltinDefaults()).TranslateGatewayOutputs(
+		context.Background(),
+		gateway,
+		newGatewayServers(),
+	)
+	if err != nil {
+		t.Fatalf("failed to translate gateway outputs: %v", err)
+	}
+	deployment := outputs[0].(*appsv1.Deployment)
+	if !hasVolumeMount(&deployment.Spec.Template.Spec.Containers[0], "jwks") {
+		t.Errorf("expected the JWKS ConfigMap to be mounted")
+	}
+}
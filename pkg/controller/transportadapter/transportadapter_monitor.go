@@ -0,0 +1,147 @@
+package transportadapter
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+const (
+	metricsPortName    = "metrics"
+	metricsPath        = "/metrics"
+	defaultMetricsPort = 15020
+)
+
+// MonitorGVK returns the Prometheus Operator GroupVersionKind of a monitor kind.
+func MonitorGVK(kind v1alpha1.MetricsMonitorKind) schema.GroupVersionKind {
+	return schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    string(kind),
+	}
+}
+
+// MonitorKinds returns the monitor kinds that are backed by a Prometheus Operator resource.
+func MonitorKinds() []v1alpha1.MetricsMonitorKind {
+	return []v1alpha1.MetricsMonitorKind{
+		v1alpha1.MetricsMonitorKindServiceMonitor,
+		v1alpha1.MetricsMonitorKindPodMonitor,
+	}
+}
+
+// serverMetrics returns the metrics settings of the MCPServer, or nil when metrics are disabled.
+func serverMetrics(server *v1alpha1.MCPServer) *v1alpha1.Metrics {
+	if server.Spec.Observability == nil {
+		return nil
+	}
+	return server.Spec.Observability.Metrics
+}
+
+// metricsPort returns the port the transport adapter serves its metrics on.
+func metricsPort(metrics *v1alpha1.Metrics) int32 {
+	if metrics.Port == 0 {
+		return defaultMetricsPort
+	}
+	return int32(metrics.Port)
+}
+
+// monitorKind returns the monitor kind requested by the metrics settings.
+func monitorKind(metrics *v1alpha1.Metrics) v1alpha1.MetricsMonitorKind {
+	if metrics.Monitor == "" {
+		return v1alpha1.MetricsMonitorKindServiceMonitor
+	}
+	return metrics.Monitor
+}
+
+// addMetricsContainerPort declares the metrics port on the container running the
+// transport adapter so that PodMonitors can select it by name.
+func addMetricsContainerPort(server *v1alpha1.MCPServer, podSpec *corev1.PodSpec) {
+	metrics := serverMetrics(server)
+	if metrics == nil || server.Spec.TransportType != v1alpha1.TransportTypeStdio {
+		return
+	}
+	adapter := &podSpec.Containers[0]
+	if stdioAdapterMode(server) == v1alpha1.StdioAdapterModeSidecar {
+		adapter = &podSpec.Containers[1]
+	}
+	adapter.Ports = append(adapter.Ports, corev1.ContainerPort{
+		Name:          metricsPortName,
+		ContainerPort: metricsPort(metrics),
+		Protocol:      corev1.ProtocolTCP,
+	})
+}
+
+// metricsServicePort returns the Service port exposing the metrics of the transport adapter.
+func metricsServicePort(metrics *v1alpha1.Metrics) corev1.ServicePort {
+	return corev1.ServicePort{
+		Name:       metricsPortName,
+		Protocol:   corev1.ProtocolTCP,
+		Port:       metricsPort(metrics),
+		TargetPort: intstr.FromString(metricsPortName),
+	}
+}
+
+// translateMonitor translates the metrics settings of the MCPServer into a Prometheus
+// Operator ServiceMonitor or PodMonitor. It returns nil when no monitor is requested or
+// when the CRD of the requested kind is not installed.
+func (t *transportAdapterTranslator) translateMonitor(server *v1alpha1.MCPServer) (client.Object, error) {
+	metrics := serverMetrics(server)
+	if metrics == nil {
+		return nil, nil
+	}
+	kind := monitorKind(metrics)
+	if !t.monitorKinds[kind] {
+		return nil, nil
+	}
+
+	endpoint := map[string]interface{}{
+		"port": metricsPortName,
+		"path": metricsPath,
+	}
+	if metrics.Interval != "" {
+		endpoint["interval"] = metrics.Interval
+	}
+
+	spec := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": toInterfaceMap(selectorLabels(server)),
+		},
+	}
+	switch kind {
+	case v1alpha1.MetricsMonitorKindServiceMonitor:
+		spec["endpoints"] = []interface{}{endpoint}
+	case v1alpha1.MetricsMonitorKindPodMonitor:
+		spec["podMetricsEndpoints"] = []interface{}{endpoint}
+	default:
+		return nil, fmt.Errorf("unsupported monitor kind: %s", kind)
+	}
+
+	labels := selectorLabels(server)
+	for key, value := range t.monitorLabels {
+		labels[key] = value
+	}
+
+	monitor := &unstructured.Unstructured{}
+	monitor.SetGroupVersionKind(MonitorGVK(kind))
+	monitor.SetName(server.Name)
+	monitor.SetNamespace(server.Namespace)
+	monitor.SetLabels(labels)
+	monitor.Object["spec"] = spec
+
+	return monitor, controllerutil.SetOwnerReference(server, monitor, t.scheme)
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		out[key] = value
+	}
+	return out
+}
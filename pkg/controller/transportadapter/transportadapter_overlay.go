@@ -0,0 +1,64 @@
+package transportadapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// applyPodTemplateOverlay strategic-merges the pod template overlay of the MCPServer over
+// the generated pod template. The patch strategies of PodTemplateSpec apply, so containers,
+// init containers and volumes are merged by name. The selector labels are restored after
+// the merge so that the workload keeps selecting its pods.
+//
+// The overlay takes precedence over the generated pod template, except for the settings
+// of the security profile and the MCPServerPolicies: the overlay is checked against them
+// by CheckSecurityProfile and CheckServerPolicy, and the profile is applied after it.
+func applyPodTemplateOverlay(
+	server *v1alpha1.MCPServer,
+	podTemplate *corev1.PodTemplateSpec,
+) (*corev1.PodTemplateSpec, error) {
+	overlay := server.Spec.Deployment.PodTemplate
+	if overlay == nil || len(overlay.Raw) == 0 {
+		return podTemplate, nil
+	}
+
+	original, err := json.Marshal(podTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pod template: %w", err)
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, overlay.Raw, corev1.PodTemplateSpec{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply deployment.podTemplate of MCPServer %s: %w", server.Name, err)
+	}
+
+	result := &corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(merged, result); err != nil {
+		return nil, fmt.Errorf("invalid deployment.podTemplate of MCPServer %s: %w", server.Name, err)
+	}
+	if result.Labels == nil {
+		result.Labels = map[string]string{}
+	}
+	for key, value := range selectorLabels(server) {
+		result.Labels[key] = value
+	}
+	return result, nil
+}
+
+// podTemplateOverlay decodes the pod template overlay of the MCPServer, or returns nil when
+// it has none.
+func podTemplateOverlay(server *v1alpha1.MCPServer) (*corev1.PodTemplateSpec, error) {
+	overlay := server.Spec.Deployment.PodTemplate
+	if overlay == nil || len(overlay.Raw) == 0 {
+		return nil, nil
+	}
+	podTemplate := &corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(overlay.Raw, podTemplate); err != nil {
+		return nil, fmt.Errorf("invalid deployment.podTemplate of MCPServer %s: %w", server.Name, err)
+	}
+	return podTemplate, nil
+}
@@ -0,0 +1,141 @@
+package transportadapter
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func withPodTemplate(server *v1alpha1.MCPServer, overlay string) *v1alpha1.MCPServer {
+	server.Spec.Deployment.PodTemplate = &runtime.RawExtension{Raw: []byte(overlay)}
+	return server
+}
+
+func TestPodTemplateOverlayPodFields(t *testing.T) {
+	server := withPodTemplate(newStdioServer(v1alpha1.StdioAdapterModeCopyBinary), `{
+		"metadata": {"labels": {"app.kubernetes.io/name": "other", "team": "search"}},
+		"spec": {
+			"priorityClassName": "high-priority",
+			"runtimeClassName": "gvisor",
+			"topologySpreadConstraints": [{
+				"maxSkew": 1,
+				"topologyKey": "topology.kubernetes.io/zone",
+				"whenUnsatisfiable": "ScheduleAnyway"
+			}]
+		}
+	}`)
+
+	template := findDeployment(t, translateOutputs(t, server)).Spec.Template
+	if template.Spec.PriorityClassName != "high-priority" || *template.Spec.RuntimeClassName != "gvisor" {
+		t.Errorf("expected the pod fields of the overlay, got %+v", template.Spec)
+	}
+	if len(template.Spec.TopologySpreadConstraints) != 1 {
+		t.Errorf("expected the topology spread constraints of the overlay")
+	}
+	// Labels are merged, but the selector labels cannot be overridden
+	if template.Labels["team"] != "search" || template.Labels["app.kubernetes.io/name"] != "test-server" {
+		t.Errorf("unexpected pod labels: %v", template.Labels)
+	}
+	if template.Annotations["kmcp.kagent.dev/mcpserver-config-hash"] == "" {
+		t.Errorf("expected the config hash annotation to be kept")
+	}
+}
+
+func TestPodTemplateOverlayContainers(t *testing.T) {
+	server := withPodTemplate(newStdioServer(v1alpha1.StdioAdapterModeCopyBinary), `{
+		"spec": {
+			"initContainers": [{"name": "wait-for-db", "image": "busybox", "command": ["true"]}],
+			"containers": [
+				{
+					"name": "mcp-server",
+					"lifecycle": {"preStop": {"exec": {"command": ["sleep", "5"]}}}
+				},
+				{"name": "log-shipper", "image": "fluent-bit"}
+			]
+		}
+	}`)
+
+	spec := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec
+
+	// Init containers are merged by name, the copy-binary init container is kept
+	if len(spec.InitContainers) != 2 ||
+		findContainer(spec.InitContainers, "copy-binary") == nil ||
+		findContainer(spec.InitContainers, "wait-for-db") == nil {
+		t.Errorf("expected the overlay init container next to copy-binary, got %+v", spec.InitContainers)
+	}
+
+	// The generated container is patched, not replaced
+	main := findContainer(spec.Containers, "mcp-server")
+	if main == nil || main.Image != "test-image:latest" || len(main.Command) == 0 {
+		t.Fatalf("expected the generated mcp-server container to be kept, got %+v", main)
+	}
+	if main.Lifecycle == nil || main.Lifecycle.PreStop == nil {
+		t.Errorf("expected the lifecycle hook of the overlay")
+	}
+	if findContainer(spec.Containers, "log-shipper") == nil {
+		t.Errorf("expected the overlay sidecar to be added")
+	}
+}
+
+func TestPodTemplateOverlayVolumes(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Spec.Deployment.Volumes = []corev1.Volume{{
+		Name:         "scratch",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}}
+	withPodTemplate(server, `{
+		"spec": {
+			"volumes": [
+				{"name": "scratch", "$patch": "delete"},
+				{"name": "cache", "emptyDir": {"medium": "Memory"}}
+			]
+		}
+	}`)
+
+	volumes := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec.Volumes
+	names := map[string]bool{}
+	for _, volume := range volumes {
+		names[volume.Name] = true
+	}
+	if !names["config"] || !names["binary"] || !names["cache"] || names["scratch"] {
+		t.Errorf("unexpected volumes after the merge: %v", names)
+	}
+}
+
+func TestPodTemplateOverlayInvalid(t *testing.T) {
+	server := withPodTemplate(newStdioServer(v1alpha1.StdioAdapterModeCopyBinary), `{"spec": {"containers": "nope"}}`)
+	translator := NewTransportAdapterTranslator(newTestScheme(t), nil)
+	if _, err := translator.TranslateTransportAdapterOutputs(context.Background(), server); err == nil {
+		t.Errorf("expected an error for an invalid pod template overlay")
+	}
+}
+
+func TestPodTemplateOverlaySecurityProfile(t *testing.T) {
+	server := withPodTemplate(newStdioServer(v1alpha1.StdioAdapterModeCopyBinary), `{
+		"spec": {
+			"containers": [
+				{"name": "mcp-server", "securityContext": null},
+				{"name": "shipper", "image": "fluent-bit"}
+			]
+		}
+	}`)
+	server.Spec.SecurityProfile = v1alpha1.SecurityProfileRestricted
+
+	spec := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec
+	for _, name := range []string{"mcp-server", "shipper"} {
+		securityContext := findContainer(spec.Containers, name).SecurityContext
+		if securityContext == nil || !*securityContext.ReadOnlyRootFilesystem || *securityContext.AllowPrivilegeEscalation {
+			t.Errorf("expected container %s to be hardened despite the overlay, got %+v", name, securityContext)
+		}
+	}
+
+	withPodTemplate(server, `{"spec": {"containers": [{"name": "mcp-server", "securityContext": {"runAsUser": 0}}]}}`)
+	translator := NewTransportAdapterTranslator(newTestScheme(t), nil)
+	if _, err := translator.TranslateTransportAdapterOutputs(context.Background(), server); err == nil {
+		t.Errorf("expected an overlay running as root to be rejected by the restricted profile")
+	}
+}
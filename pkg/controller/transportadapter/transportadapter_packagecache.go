@@ -0,0 +1,188 @@
+package transportadapter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+const (
+	packageCacheVolumeName    = "package-cache"
+	packageCacheDir           = "/kmcp-cache"
+	packageRegistryVolumeName = "package-registry"
+	packageRegistryDir        = "/kmcp-registry"
+	packageInstallName        = "package-install"
+)
+
+// CheckPackageCache checks that the package cache of the MCPServer can be applied to the
+// runner of its command, and that the package to pre-install can be found in its
+// arguments.
+func CheckPackageCache(server *v1alpha1.MCPServer, defaults Defaults) error {
+	packageCache := server.Spec.Deployment.PackageCache
+	if packageCache == nil {
+		return nil
+	}
+	cmd := server.Spec.Deployment.Cmd
+	runner, ok := defaults.LookupRunner(cmd)
+	if !ok || len(runner.CacheEnv) == 0 {
+		return fmt.Errorf("deployment.packageCache requires deployment.cmd to be a runner with a cache, such as 'npx' or 'uvx'")
+	}
+	if packageCache.Type == v1alpha1.PackageCacheTypePersistentVolumeClaim && packageCache.ClaimName == "" {
+		return fmt.Errorf("deployment.packageCache.claimName is required when type is 'PersistentVolumeClaim'")
+	}
+	if packageCache.PreInstall {
+		if len(runner.InstallCommand) == 0 {
+			return fmt.Errorf("deployment.packageCache.preInstall is not supported by the runner %s", cmd)
+		}
+		if packageToInstall(runner, server.Spec.Deployment.Args) == "" {
+			return fmt.Errorf("deployment.packageCache.preInstall requires the package in the arguments of %s", cmd)
+		}
+	}
+	return nil
+}
+
+// packageToInstall returns the package run by the runner: the value of one of its package
+// flags, such as --package or --from, or else the first positional argument.
+func packageToInstall(runner Runner, args []string) string {
+	positional := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if name, value, ok := strings.Cut(arg, "="); ok && slices.Contains(runner.PackageFlags, name) {
+			return value
+		}
+		if slices.Contains(runner.PackageFlags, arg) && i+1 < len(args) {
+			return args[i+1]
+		}
+		if slices.Contains(runner.ValueFlags, arg) {
+			i++
+			continue
+		}
+		if positional == "" && !strings.HasPrefix(arg, "-") {
+			positional = arg
+		}
+	}
+	return positional
+}
+
+// packageCacheEnv returns the environment variables pointing the runner at the cache.
+func packageCacheEnv(runner Runner, packageCache *v1alpha1.PackageCache) []corev1.EnvVar {
+	env := make([]corev1.EnvVar, 0, len(runner.CacheEnv)+2)
+	for name, dir := range runner.CacheEnv {
+		env = append(env, corev1.EnvVar{Name: name, Value: packageCacheDir + "/" + dir})
+	}
+	sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+	if runner.Ecosystem != PackageEcosystemNPM {
+		return env
+	}
+	if packageCache.PreInstall {
+		// npm resolves the package from the cache filled by the init container instead of
+		// looking for a newer version
+		env = append(env, corev1.EnvVar{Name: "npm_config_prefer_offline", Value: "true"})
+	}
+	if packageCache.RegistrySecretRef != nil {
+		env = append(env, corev1.EnvVar{Name: "npm_config_userconfig", Value: packageRegistryDir + "/.npmrc"})
+	}
+	return env
+}
+
+// applyPackageCache mounts the package cache and the registry settings in the MCP server
+// container and adds the init container pre-installing the package. It runs before the
+// security profile so that the cache settings take precedence over the writable
+// directories of the profile and the init container is hardened like the others.
+func applyPackageCache(
+	server *v1alpha1.MCPServer,
+	defaults Defaults,
+	image string,
+	pullPolicy corev1.PullPolicy,
+	podSpec *corev1.PodSpec,
+) {
+	packageCache := server.Spec.Deployment.PackageCache
+	runner, ok := defaults.LookupRunner(server.Spec.Deployment.Cmd)
+	if packageCache == nil || !ok {
+		return
+	}
+	main := findContainer(podSpec.Containers, "mcp-server")
+	if main == nil {
+		return
+	}
+
+	cacheVolume := corev1.Volume{Name: packageCacheVolumeName}
+	if packageCache.Type == v1alpha1.PackageCacheTypePersistentVolumeClaim {
+		cacheVolume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: packageCache.ClaimName,
+		}
+	} else {
+		cacheVolume.EmptyDir = &corev1.EmptyDirVolumeSource{SizeLimit: packageCache.SizeLimit}
+	}
+	podSpec.Volumes = append(podSpec.Volumes, cacheVolume)
+	mounts := []corev1.VolumeMount{{Name: packageCacheVolumeName, MountPath: packageCacheDir}}
+
+	var envFrom []corev1.EnvFromSource
+	if secretRef := packageCache.RegistrySecretRef; secretRef != nil {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: packageRegistryVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: secretRef.Name},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      packageRegistryVolumeName,
+			MountPath: packageRegistryDir,
+			ReadOnly:  true,
+		})
+		// Keys that are not valid variable names, such as .npmrc, are skipped by the kubelet
+		envFrom = append(envFrom, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: *secretRef},
+		})
+	}
+
+	env := packageCacheEnv(runner, packageCache)
+	main.VolumeMounts = append(main.VolumeMounts, mounts...)
+	main.EnvFrom = append(main.EnvFrom, envFrom...)
+	for _, envVar := range env {
+		if !hasEnvVar(main, envVar.Name) {
+			main.Env = append(main.Env, envVar)
+		}
+	}
+
+	if !packageCache.PreInstall {
+		return
+	}
+	// The arguments of the runner were prepended to the arguments of the MCPServer
+	pkg := packageToInstall(runner, server.Spec.Deployment.Args[len(runner.Args):])
+	installCmd := make([]string, 0, len(runner.InstallCommand))
+	for _, arg := range runner.InstallCommand {
+		installCmd = append(installCmd, strings.ReplaceAll(arg, runnerPackagePlaceholder, pkg))
+	}
+	podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+		Name:            packageInstallName,
+		Image:           image,
+		ImagePullPolicy: pullPolicy,
+		Command:         installCmd,
+		Env: append([]corev1.EnvVar{
+			{Name: "HOME", Value: packageCacheDir + "/home"},
+			{Name: "TMPDIR", Value: packageCacheDir},
+		}, env...),
+		EnvFrom:         envFrom,
+		VolumeMounts:    mounts,
+		Resources:       main.Resources,
+		SecurityContext: main.SecurityContext,
+	})
+}
+
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package transportadapter
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func envValue(container *corev1.Container, name string) string {
+	for _, envVar := range container.Env {
+		if envVar.Name == name {
+			return envVar.Value
+		}
+	}
+	return ""
+}
+
+func TestPackageCacheEmptyDir(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	sizeLimit := resource.MustParse("1Gi")
+	server.Spec.Deployment.PackageCache = &v1alpha1.PackageCache{SizeLimit: &sizeLimit}
+
+	spec := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec
+	var cacheVolume *corev1.Volume
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Name == packageCacheVolumeName {
+			cacheVolume = &spec.Volumes[i]
+		}
+	}
+	if cacheVolume == nil || cacheVolume.EmptyDir == nil || !cacheVolume.EmptyDir.SizeLimit.Equal(sizeLimit) {
+		t.Fatalf("expected a size limited emptyDir cache, got %+v", cacheVolume)
+	}
+
+	main := findContainer(spec.Containers, "mcp-server")
+	if !hasVolumeMount(main, packageCacheVolumeName) || envValue(main, "npm_config_cache") != "/kmcp-cache/npm" {
+		t.Errorf("expected the npm cache on the cache volume, got %+v", main.Env)
+	}
+	if findContainer(spec.InitContainers, packageInstallName) != nil {
+		t.Errorf("expected no pre-install init container without preInstall")
+	}
+}
+
+func TestPackageCachePreInstall(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     string
+		args    []string
+		install []string
+	}{
+		{
+			name:    "npx positional package",
+			cmd:     "npx",
+			args:    []string{"-y", "@modelcontextprotocol/server-filesystem@1.0.0", "/data"},
+			install: []string{"npm", "exec", "--yes", "--package=@modelcontextprotocol/server-filesystem@1.0.0", "--", "true"},
+		},
+		{
+			name:    "npx package flag",
+			cmd:     "npx",
+			args:    []string{"--yes", "--package", "mcp-remote", "mcp-remote-client", "https://example.com"},
+			install: []string{"npm", "exec", "--yes", "--package=mcp-remote", "--", "true"},
+		},
+		{
+			name:    "uvx after value flags",
+			cmd:     "uvx",
+			args:    []string{"--python", "3.12", "mcp-server-fetch", "--ignore-robots-txt"},
+			install: []string{"uv", "tool", "install", "mcp-server-fetch"},
+		},
+		{
+			name:    "uvx from flag",
+			cmd:     "uvx",
+			args:    []string{"--from=mcp-server-git==0.6.2", "mcp-server-git"},
+			install: []string{"uv", "tool", "install", "mcp-server-git==0.6.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+			server.Spec.Deployment.Cmd = tt.cmd
+			server.Spec.Deployment.Args = tt.args
+			server.Spec.Deployment.PackageCache = &v1alpha1.PackageCache{
+				Type:       v1alpha1.PackageCacheTypePersistentVolumeClaim,
+				ClaimName:  "mcp-packages",
+				PreInstall: true,
+			}
+			if err := CheckPackageCache(server, BuiltinDefaults()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			spec := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec
+			if len(spec.InitContainers) != 2 || spec.InitContainers[0].Name != "copy-binary" {
+				t.Fatalf("expected the pre-install init container after copy-binary, got %+v", spec.InitContainers)
+			}
+			install := spec.InitContainers[1]
+			if install.Name != packageInstallName || install.Image != "test-image:latest" {
+				t.Errorf("expected the pre-install init container to use the server image, got %+v", install)
+			}
+			if !slices.Equal(install.Command, tt.install) {
+				t.Errorf("expected install command %v, got %v", tt.install, install.Command)
+			}
+			if !hasVolumeMount(&install, packageCacheVolumeName) {
+				t.Errorf("expected the cache to be mounted in the init container")
+			}
+			for _, volume := range spec.Volumes {
+				if volume.Name == packageCacheVolumeName &&
+					(volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != "mcp-packages") {
+					t.Errorf("expected the cache to use the claim, got %+v", volume)
+				}
+			}
+		})
+	}
+}
+
+func TestPackageCacheRegistrySecret(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.SecurityProfile = v1alpha1.SecurityProfileRestricted
+	server.Spec.Deployment.PackageCache = &v1alpha1.PackageCache{
+		PreInstall:        true,
+		RegistrySecretRef: &corev1.LocalObjectReference{Name: "registry"},
+	}
+
+	spec := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec
+	for _, container := range []*corev1.Container{
+		findContainer(spec.Containers, "mcp-server"),
+		findContainer(spec.InitContainers, packageInstallName),
+	} {
+		if !hasVolumeMount(container, packageRegistryVolumeName) ||
+			envValue(container, "npm_config_userconfig") != "/kmcp-registry/.npmrc" {
+			t.Errorf("expected the .npmrc of the registry secret in %s", container.Name)
+		}
+		if len(container.EnvFrom) == 0 || container.EnvFrom[len(container.EnvFrom)-1].SecretRef.Name != "registry" {
+			t.Errorf("expected the registry secret as environment of %s, got %+v", container.Name, container.EnvFrom)
+		}
+		// The cache takes precedence over the writable directories of the security profile
+		if envValue(container, "npm_config_cache") != "/kmcp-cache/npm" {
+			t.Errorf("expected the npm cache on the cache volume in %s", container.Name)
+		}
+		if container.SecurityContext == nil || !*container.SecurityContext.ReadOnlyRootFilesystem {
+			t.Errorf("expected %s to be hardened, got %+v", container.Name, container.SecurityContext)
+		}
+	}
+}
+
+func TestCheckPackageCache(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Spec.Deployment.Cmd = "python"
+	server.Spec.Deployment.PackageCache = &v1alpha1.PackageCache{}
+	if err := CheckPackageCache(server, BuiltinDefaults()); err == nil {
+		t.Errorf("expected an error for a command other than npx or uvx")
+	}
+
+	server.Spec.Deployment.Cmd = "uvx"
+	server.Spec.Deployment.Args = []string{"--with", "httpx"}
+	server.Spec.Deployment.PackageCache.PreInstall = true
+	if err := CheckPackageCache(server, BuiltinDefaults()); err == nil {
+		t.Errorf("expected an error when the package cannot be found in the arguments")
+	}
+}
@@ -0,0 +1,209 @@
+package transportadapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// policyContainer is a container of an MCPServer checked by an MCPServerPolicy.
+type policyContainer struct {
+	// field is the field of the MCPServer spec the container is set by.
+	field           string
+	image           string
+	securityContext *corev1.SecurityContext
+	resources       *corev1.ResourceRequirements
+	// requireLimits is whether the resource limits of the container are checked.
+	requireLimits bool
+}
+
+// CheckServerPolicy checks the MCPServer against the MCPServerPolicy, and explains the
+// violations. The policy is expected to select the namespace of the MCPServer. The
+// defaults resolve the runner of the MCPServer command.
+func CheckServerPolicy(server *v1alpha1.MCPServer, policy *v1alpha1.MCPServerPolicy, defaults Defaults) error {
+	spec := policy.Spec
+	containers, overlay := policyContainers(server, defaults)
+
+	var violations []string
+	for _, container := range containers {
+		if len(spec.AllowedImages) > 0 && container.image != "" && !imageAllowed(container.image, spec.AllowedImages) {
+			violations = append(violations, fmt.Sprintf("%s: image %s is not allowed", container.field, container.image))
+		}
+		if securityContext := container.securityContext; securityContext != nil {
+			if spec.DenyPrivileged && securityContext.Privileged != nil && *securityContext.Privileged {
+				violations = append(violations, container.field+": privileged containers are not allowed")
+			}
+			if len(spec.AllowedCapabilities) > 0 && securityContext.Capabilities != nil {
+				for _, capability := range securityContext.Capabilities.Add {
+					if !slices.Contains(spec.AllowedCapabilities, capability) {
+						violations = append(violations,
+							fmt.Sprintf("%s: capability %s is not allowed", container.field, capability))
+					}
+				}
+			}
+		}
+		if spec.RequireResourceLimits && container.requireLimits {
+			for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				if container.resources == nil || container.resources.Limits.Name(resourceName, "").IsZero() {
+					violations = append(violations, fmt.Sprintf("%s: a %s limit is required", container.field, resourceName))
+				}
+			}
+		}
+	}
+
+	if spec.DenyPrivileged && overlay != nil &&
+		(overlay.Spec.HostNetwork || overlay.Spec.HostPID || overlay.Spec.HostIPC) {
+		violations = append(violations,
+			"deployment.podTemplate: sharing the host network, PID or IPC namespaces is not allowed")
+	}
+
+	runner, ok := defaults.LookupRunner(server.Spec.Deployment.Cmd)
+	if len(spec.AllowedPackages) > 0 && ok && runner.Ecosystem != "" {
+		packages := packagesToRun(runner, server.Spec.Deployment.Args)
+		if len(packages) == 0 {
+			violations = append(violations, fmt.Sprintf("deployment.args: the package run by %s is not found", runner.Name))
+		}
+		for _, pkg := range packages {
+			if !packageAllowed(packageName(runner.Ecosystem, pkg), spec.AllowedPackages) {
+				violations = append(violations, fmt.Sprintf("deployment.args: package %s is not allowed", pkg))
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("MCPServerPolicy %s is violated: %s", policy.Name, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// policyContainers returns the containers of the MCPServer, and its pod template overlay.
+// The images of the transport adapter are only returned when they are set on the
+// MCPServer, since the images chosen by the controller are trusted.
+func policyContainers(server *v1alpha1.MCPServer, defaults Defaults) ([]policyContainer, *corev1.PodTemplateSpec) {
+	deployment := server.Spec.Deployment
+	containers := []policyContainer{{
+		field:           "deployment",
+		image:           serverImage(server, defaults),
+		securityContext: deployment.SecurityContext,
+		resources:       deployment.Resources,
+		requireLimits:   true,
+	}}
+	if initContainer := deployment.InitContainer; initContainer != nil {
+		containers = append(containers, policyContainer{
+			field:           "deployment.initContainer",
+			image:           initContainer.Image,
+			securityContext: initContainer.SecurityContext,
+		})
+	}
+	if stdio := server.Spec.StdioTransport; stdio != nil && stdio.BridgeImage != "" {
+		containers = append(containers, policyContainer{field: "stdioTransport.bridgeImage", image: stdio.BridgeImage})
+	}
+	for i := range deployment.Sidecars {
+		sidecar := &deployment.Sidecars[i]
+		containers = append(containers, policyContainer{
+			field:           fmt.Sprintf("deployment.sidecars[%s]", sidecar.Name),
+			image:           sidecar.Image,
+			securityContext: sidecar.SecurityContext,
+			resources:       &sidecar.Resources,
+			requireLimits:   true,
+		})
+	}
+
+	// The overlay is validated when it is applied, an invalid overlay is not checked here
+	if deployment.PodTemplate == nil || len(deployment.PodTemplate.Raw) == 0 {
+		return containers, nil
+	}
+	overlay := &corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(deployment.PodTemplate.Raw, overlay); err != nil {
+		return containers, nil
+	}
+	for _, container := range overlay.Spec.InitContainers {
+		containers = append(containers, policyContainer{
+			field:           fmt.Sprintf("deployment.podTemplate.spec.initContainers[%s]", container.Name),
+			image:           container.Image,
+			securityContext: container.SecurityContext,
+		})
+	}
+	for _, container := range overlay.Spec.Containers {
+		containers = append(containers, policyContainer{
+			field:           fmt.Sprintf("deployment.podTemplate.spec.containers[%s]", container.Name),
+			image:           container.Image,
+			securityContext: container.SecurityContext,
+		})
+	}
+	return containers, overlay
+}
+
+// imageAllowed reports whether the image matches one of the allowed images: a prefix
+// ending with /, or an image with any tag or digest.
+func imageAllowed(image string, allowed []string) bool {
+	for _, entry := range allowed {
+		if strings.HasSuffix(entry, "/") {
+			if strings.HasPrefix(image, entry) {
+				return true
+			}
+			continue
+		}
+		if image == entry || strings.HasPrefix(image, entry+":") || strings.HasPrefix(image, entry+"@") {
+			return true
+		}
+	}
+	return false
+}
+
+// packagesToRun returns the packages the runner runs: the package of the MCP server and
+// the additional packages of uvx --with.
+func packagesToRun(runner Runner, args []string) []string {
+	var packages []string
+	if pkg := packageToInstall(runner, args); pkg != "" {
+		packages = append(packages, pkg)
+	}
+	if runner.Ecosystem != PackageEcosystemPyPI {
+		return packages
+	}
+	for i := 0; i < len(args) && args[i] != "--"; i++ {
+		var with string
+		if value, ok := strings.CutPrefix(args[i], "--with="); ok {
+			with = value
+		} else if args[i] == "--with" && i+1 < len(args) {
+			i++
+			with = args[i]
+		}
+		for _, pkg := range strings.Split(with, ",") {
+			if pkg = strings.TrimSpace(pkg); pkg != "" {
+				packages = append(packages, pkg)
+			}
+		}
+	}
+	return packages
+}
+
+// packageName strips the version from a package specifier of npm, such as
+// @scope/name@1.0.0, or of PyPI, such as name==1.0.0 or name[extra]>=1.0.
+func packageName(ecosystem PackageEcosystem, pkg string) string {
+	if ecosystem == PackageEcosystemNPM {
+		if i := strings.LastIndex(pkg, "@"); i > 0 {
+			return pkg[:i]
+		}
+		return pkg
+	}
+	if i := strings.IndexAny(pkg, "=<>!~@[; "); i > 0 {
+		return pkg[:i]
+	}
+	return pkg
+}
+
+func packageAllowed(name string, allowed []string) bool {
+	for _, pattern := range allowed {
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
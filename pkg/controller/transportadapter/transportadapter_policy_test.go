@@ -0,0 +1,168 @@
+package transportadapter
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func newPolicyServer() *v1alpha1.MCPServer {
+	limits := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("500m"),
+		corev1.ResourceMemory: resource.MustParse("256Mi"),
+	}
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Spec.Deployment.Image = ""
+	server.Spec.Deployment.Resources = &corev1.ResourceRequirements{Limits: limits}
+	server.Spec.Deployment.Sidecars[0].Image = "ghcr.io/our-org/sidecar:1.0"
+	server.Spec.Deployment.Sidecars[0].Resources.Limits = limits
+	return server
+}
+
+func newPolicy(spec v1alpha1.MCPServerPolicySpec) *v1alpha1.MCPServerPolicy {
+	return &v1alpha1.MCPServerPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "guardrails"},
+		Spec:       spec,
+	}
+}
+
+func TestCheckServerPolicyAllowed(t *testing.T) {
+	policy := newPolicy(v1alpha1.MCPServerPolicySpec{
+		AllowedImages:         []string{"ghcr.io/our-org/", "node"},
+		AllowedPackages:       []string{"@modelcontextprotocol/*"},
+		DenyPrivileged:        true,
+		AllowedCapabilities:   []corev1.Capability{"NET_BIND_SERVICE"},
+		RequireResourceLimits: true,
+	})
+	server := newPolicyServer()
+	server.Spec.Deployment.Args = []string{"-y", "@modelcontextprotocol/server-everything@2025.1.0"}
+	server.Spec.Deployment.SecurityContext = &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_BIND_SERVICE"}},
+	}
+
+	if err := CheckServerPolicy(server, policy, BuiltinDefaults()); err != nil {
+		t.Errorf("expected the MCPServer to respect the policy, got %v", err)
+	}
+}
+
+func TestCheckServerPolicyViolations(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   v1alpha1.MCPServerPolicySpec
+		mutate func(*v1alpha1.MCPServer)
+		err    string
+	}{
+		{
+			name: "image outside the allowed registry",
+			spec: v1alpha1.MCPServerPolicySpec{AllowedImages: []string{"ghcr.io/our-org/"}},
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.Image = "ghcr.io/our-org-fork/server:1.0"
+			},
+			err: "deployment: image ghcr.io/our-org-fork/server:1.0 is not allowed",
+		},
+		{
+			name: "default image of npx",
+			spec: v1alpha1.MCPServerPolicySpec{AllowedImages: []string{"ghcr.io/our-org/"}},
+			err:  "deployment: image node:24-alpine3.21 is not allowed",
+		},
+		{
+			name: "image of the pod template overlay",
+			spec: v1alpha1.MCPServerPolicySpec{AllowedImages: []string{"ghcr.io/our-org/", "node"}},
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.PodTemplate = &runtime.RawExtension{
+					Raw: []byte(`{"spec": {"containers": [{"name": "debug", "image": "busybox"}]}}`),
+				}
+			},
+			err: "deployment.podTemplate.spec.containers[debug]: image busybox is not allowed",
+		},
+		{
+			name: "package outside the allowlist",
+			spec: v1alpha1.MCPServerPolicySpec{AllowedPackages: []string{"@modelcontextprotocol/*"}},
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.Args = []string{"-y", "@evil/server@1.0.0"}
+			},
+			err: "deployment.args: package @evil/server@1.0.0 is not allowed",
+		},
+		{
+			name: "additional uvx package",
+			spec: v1alpha1.MCPServerPolicySpec{AllowedPackages: []string{"mcp-server-fetch"}},
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.Cmd = "uvx"
+				server.Spec.Deployment.Args = []string{"--with", "requests==2.0", "mcp-server-fetch==0.6.2"}
+			},
+			err: "deployment.args: package requests==2.0 is not allowed",
+		},
+		{
+			name: "privileged sidecar",
+			spec: v1alpha1.MCPServerPolicySpec{DenyPrivileged: true},
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.Sidecars[0].SecurityContext = &corev1.SecurityContext{Privileged: makePtr(true)}
+			},
+			err: "deployment.sidecars[user-sidecar]: privileged containers are not allowed",
+		},
+		{
+			name: "host network",
+			spec: v1alpha1.MCPServerPolicySpec{DenyPrivileged: true},
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.PodTemplate = &runtime.RawExtension{Raw: []byte(`{"spec": {"hostNetwork": true}}`)}
+			},
+			err: "sharing the host network, PID or IPC namespaces is not allowed",
+		},
+		{
+			name: "capability outside the allowlist",
+			spec: v1alpha1.MCPServerPolicySpec{AllowedCapabilities: []corev1.Capability{"NET_BIND_SERVICE"}},
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.SecurityContext = &corev1.SecurityContext{
+					Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}},
+				}
+			},
+			err: "deployment: capability NET_ADMIN is not allowed",
+		},
+		{
+			name: "missing resource limits",
+			spec: v1alpha1.MCPServerPolicySpec{RequireResourceLimits: true},
+			mutate: func(server *v1alpha1.MCPServer) {
+				delete(server.Spec.Deployment.Sidecars[0].Resources.Limits, corev1.ResourceMemory)
+			},
+			err: "deployment.sidecars[user-sidecar]: a memory limit is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPolicyServer()
+			if tt.mutate != nil {
+				tt.mutate(server)
+			}
+			err := CheckServerPolicy(server, newPolicy(tt.spec), BuiltinDefaults())
+			if err == nil || !strings.Contains(err.Error(), tt.err) ||
+				!strings.Contains(err.Error(), "MCPServerPolicy guardrails") {
+				t.Errorf("expected an error of policy guardrails containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestImageAllowed(t *testing.T) {
+	allowed := []string{"ghcr.io/our-org/", "node"}
+	for image, expected := range map[string]bool{
+		"ghcr.io/our-org/server:1.0":     true,
+		"ghcr.io/our-org/tools/fetch":    true,
+		"ghcr.io/our-org-fork/server":    false,
+		"node":                           true,
+		"node:24-alpine3.21":             true,
+		"node@sha256:0123456789abcdef":   true,
+		"nodejs:24":                      false,
+		"docker.io/library/node:24-slim": false,
+	} {
+		if imageAllowed(image, allowed) != expected {
+			t.Errorf("expected imageAllowed(%q) to be %v", image, expected)
+		}
+	}
+}
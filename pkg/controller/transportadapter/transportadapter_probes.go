@@ -0,0 +1,88 @@
+package transportadapter
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// The startup probe of HTTP MCP servers allows npx and uvx up to five minutes to install
+// the MCP server package before the liveness probe takes over.
+const (
+	startupProbePeriodSeconds    = 5
+	startupProbeFailureThreshold = 60
+)
+
+// applyProbes sets the probes of the container serving MCP traffic: the transport adapter
+// for stdio MCP servers and the MCP server container for HTTP MCP servers. Probes that are
+// not configured on the MCPServer use MCP-aware defaults.
+func applyProbes(server *v1alpha1.MCPServer, backend AdapterBackend, podSpec *corev1.PodSpec) {
+	probes := server.Spec.Deployment.Probes
+	if probes != nil && probes.Disabled {
+		return
+	}
+	if probes == nil {
+		probes = &v1alpha1.Probes{}
+	}
+
+	container := &podSpec.Containers[0]
+	stdio := server.Spec.TransportType == v1alpha1.TransportTypeStdio
+	var readiness corev1.ProbeHandler
+	if stdio {
+		if stdioAdapterMode(server) == v1alpha1.StdioAdapterModeSidecar {
+			container = &podSpec.Containers[1]
+		}
+		readiness = backend.ReadinessProbe(server)
+	} else {
+		path := probes.ReadinessPath
+		if path == "" {
+			path, _ = httpEndpoint(server)
+		}
+		readiness = corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: path,
+				Port: intstr.FromInt32(int32(server.Spec.Deployment.Port)),
+			},
+		}
+	}
+
+	container.LivenessProbe = probes.Liveness
+	if container.LivenessProbe == nil {
+		container.LivenessProbe = &corev1.Probe{
+			ProbeHandler:     tcpProbeHandler(int32(server.Spec.Deployment.Port)),
+			PeriodSeconds:    10,
+			TimeoutSeconds:   1,
+			FailureThreshold: 3,
+		}
+	}
+	container.ReadinessProbe = probes.Readiness
+	if container.ReadinessProbe == nil {
+		container.ReadinessProbe = &corev1.Probe{
+			ProbeHandler:     readiness,
+			PeriodSeconds:    5,
+			TimeoutSeconds:   1,
+			FailureThreshold: 3,
+		}
+	}
+	// The adapter of stdio MCP servers serves its port right away, only HTTP MCP servers
+	// may take long to install before they listen
+	container.StartupProbe = probes.Startup
+	if container.StartupProbe == nil && !stdio {
+		container.StartupProbe = &corev1.Probe{
+			ProbeHandler:     tcpProbeHandler(int32(server.Spec.Deployment.Port)),
+			PeriodSeconds:    startupProbePeriodSeconds,
+			TimeoutSeconds:   1,
+			FailureThreshold: startupProbeFailureThreshold,
+		}
+	}
+}
+
+// tcpProbeHandler checks that the port accepts connections.
+func tcpProbeHandler(port int32) corev1.ProbeHandler {
+	return corev1.ProbeHandler{
+		TCPSocket: &corev1.TCPSocketAction{
+			Port: intstr.FromInt32(port),
+		},
+	}
+}
@@ -0,0 +1,194 @@
+package transportadapter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// PackageEcosystem is the package ecosystem of a runner, which determines how the
+// package it runs is found in its arguments and how its version is stripped.
+type PackageEcosystem string
+
+const (
+	// PackageEcosystemNPM is the ecosystem of runners of npm packages, such as npx.
+	PackageEcosystemNPM PackageEcosystem = "npm"
+	// PackageEcosystemPyPI is the ecosystem of runners of Python packages, such as uvx.
+	PackageEcosystemPyPI PackageEcosystem = "pypi"
+
+	// runnerPackagePlaceholder is replaced with the package in the install command.
+	runnerPackagePlaceholder = "$(package)"
+)
+
+// Runner is a command that runs MCP server packages, such as npx or uvx. MCPServers
+// whose deployment.cmd is a registered runner do not need an image.
+type Runner struct {
+	// Name is the command of the runner, matched against deployment.cmd.
+	Name string `json:"name"`
+	// Image is the default image of MCP servers run with the runner.
+	Image string `json:"image,omitempty"`
+	// Args are prepended to the arguments of the MCP servers run with the runner.
+	Args []string `json:"args,omitempty"`
+	// Env are environment variables of the MCP servers run with the runner, unless the
+	// MCPServer sets them.
+	Env map[string]string `json:"env,omitempty"`
+	// CacheEnv maps the environment variables selecting the cache directories of the
+	// runner to directories of the package cache.
+	CacheEnv map[string]string `json:"cacheEnv,omitempty"`
+	// Ecosystem is the package ecosystem of the runner. Package policies and package
+	// pre-installation only apply to runners of a known ecosystem.
+	Ecosystem PackageEcosystem `json:"ecosystem,omitempty"`
+	// PackageFlags are the flags naming the package to run, such as --package.
+	PackageFlags []string `json:"packageFlags,omitempty"`
+	// ValueFlags are the flags taking a separate value, skipped when looking for the
+	// package in the arguments.
+	ValueFlags []string `json:"valueFlags,omitempty"`
+	// InstallCommand pre-installs $(package) in the package cache. Package
+	// pre-installation is not supported when empty.
+	InstallCommand []string `json:"installCommand,omitempty"`
+}
+
+// BuiltinRunners returns the runners registered by default. pipx is not built in, since
+// no official image ships it, and neither are docker-style runners, which need a
+// container runtime in the pod; both can be registered in the controller configuration.
+func BuiltinRunners() []Runner {
+	return []Runner{
+		{
+			Name:      "npx",
+			Image:     "node:24-alpine3.21",
+			CacheEnv:  map[string]string{"npm_config_cache": "npm"},
+			Ecosystem: PackageEcosystemNPM,
+			// -p is --package for npx
+			PackageFlags:   []string{"--package", "-p"},
+			ValueFlags:     []string{"--cache", "--registry", "--userconfig", "--call", "-c"},
+			InstallCommand: []string{"npm", "exec", "--yes", "--package=" + runnerPackagePlaceholder, "--", "true"},
+		},
+		{
+			Name:  "uvx",
+			Image: "ghcr.io/astral-sh/uv:debian",
+			CacheEnv: map[string]string{
+				"UV_CACHE_DIR":    "uv",
+				"UV_TOOL_DIR":     "uv-tools",
+				"UV_TOOL_BIN_DIR": "uv-tools/bin",
+			},
+			Ecosystem:    PackageEcosystemPyPI,
+			PackageFlags: []string{"--from"},
+			ValueFlags: []string{
+				"--with", "--with-editable", "--with-requirements", "--python", "-p",
+				"--index", "--index-url", "--extra-index-url", "--default-index",
+				"--constraint", "--overrides", "--cache-dir", "--directory",
+			},
+			InstallCommand: []string{"uv", "tool", "install", runnerPackagePlaceholder},
+		},
+		{
+			Name:         "bunx",
+			Image:        "oven/bun:1",
+			CacheEnv:     map[string]string{"BUN_INSTALL_CACHE_DIR": "bun"},
+			Ecosystem:    PackageEcosystemNPM,
+			PackageFlags: []string{"--package", "-p"},
+		},
+		{
+			// deno runs the npm: and jsr: specifiers of its arguments
+			Name:     "deno",
+			Image:    "denoland/deno:debian",
+			Args:     []string{"run"},
+			CacheEnv: map[string]string{"DENO_DIR": "deno"},
+		},
+	}
+}
+
+// MergeRunners merges the runners of overrides into runners: the fields set on an
+// override replace the fields of the runner of the same name, and overrides of other
+// names register new runners.
+func MergeRunners(runners []Runner, overrides []Runner) []Runner {
+	merged := slices.Clone(runners)
+	for _, override := range overrides {
+		i := slices.IndexFunc(merged, func(runner Runner) bool { return runner.Name == override.Name })
+		if i < 0 {
+			merged = append(merged, override)
+			continue
+		}
+		runner := &merged[i]
+		if override.Image != "" {
+			runner.Image = override.Image
+		}
+		if override.Args != nil {
+			runner.Args = override.Args
+		}
+		if override.Env != nil {
+			runner.Env = override.Env
+		}
+		if override.CacheEnv != nil {
+			runner.CacheEnv = override.CacheEnv
+		}
+		if override.Ecosystem != "" {
+			runner.Ecosystem = override.Ecosystem
+		}
+		if override.PackageFlags != nil {
+			runner.PackageFlags = override.PackageFlags
+		}
+		if override.ValueFlags != nil {
+			runner.ValueFlags = override.ValueFlags
+		}
+		if override.InstallCommand != nil {
+			runner.InstallCommand = override.InstallCommand
+		}
+	}
+	return merged
+}
+
+// CheckRunner checks that the runner can be used to run MCP servers.
+func CheckRunner(runner Runner) error {
+	if runner.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if runner.Image == "" {
+		return fmt.Errorf("image is required")
+	}
+	switch runner.Ecosystem {
+	case "", PackageEcosystemNPM, PackageEcosystemPyPI:
+	default:
+		return fmt.Errorf("unknown ecosystem %q, must be one of %s or %s",
+			runner.Ecosystem, PackageEcosystemNPM, PackageEcosystemPyPI)
+	}
+	if len(runner.InstallCommand) > 0 {
+		if runner.Ecosystem == "" {
+			return fmt.Errorf("installCommand requires an ecosystem")
+		}
+		if !slices.ContainsFunc(runner.InstallCommand, func(arg string) bool {
+			return strings.Contains(arg, runnerPackagePlaceholder)
+		}) {
+			return fmt.Errorf("installCommand must reference the package as %s", runnerPackagePlaceholder)
+		}
+	}
+	return nil
+}
+
+// applyRunner returns a copy of the MCPServer with the arguments and environment of its
+// runner applied. The MCPServer is returned as is when its command is not a runner.
+func applyRunner(server *v1alpha1.MCPServer, defaults Defaults) *v1alpha1.MCPServer {
+	runner, ok := defaults.LookupRunner(server.Spec.Deployment.Cmd)
+	if !ok || (len(runner.Args) == 0 && len(runner.Env) == 0) {
+		return server
+	}
+	merged := server.DeepCopy()
+	deployment := &merged.Spec.Deployment
+	if len(runner.Args) > 0 {
+		deployment.Args = append(slices.Clone(runner.Args), deployment.Args...)
+	}
+	for name, value := range runner.Env {
+		_, ok := deployment.Env[name]
+		if ok || slices.ContainsFunc(deployment.EnvVars, func(envVar corev1.EnvVar) bool { return envVar.Name == name }) {
+			continue
+		}
+		if deployment.Env == nil {
+			deployment.Env = map[string]string{}
+		}
+		deployment.Env[name] = value
+	}
+	return merged
+}
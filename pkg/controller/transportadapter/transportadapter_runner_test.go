@@ -0,0 +1,100 @@
+package transportadapter
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func TestRunnerDefaults(t *testing.T) {
+	defaults := BuiltinDefaults()
+	defaults.Runners = MergeRunners(defaults.Runners, []Runner{{
+		Name: "deno",
+		Env:  map[string]string{"DENO_NO_UPDATE_CHECK": "1", "FOO": "runner"},
+	}})
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.Deployment.Image = ""
+	server.Spec.Deployment.Cmd = "deno"
+	server.Spec.Deployment.Args = []string{"-A", "jsr:@example/mcp-server"}
+
+	main := findContainer(
+		findDeployment(t, translateOutputs(t, server, WithDefaults(defaults))).Spec.Template.Spec.Containers,
+		"mcp-server",
+	)
+	if main.Image != "denoland/deno:debian" {
+		t.Errorf("expected the image of the deno runner, got %s", main.Image)
+	}
+	if !slices.Equal(main.Command[4:], []string{"deno", "run", "-A", "jsr:@example/mcp-server"}) {
+		t.Errorf("expected the arguments of the runner before the arguments of the server, got %v", main.Command)
+	}
+	if envValue(main, "DENO_NO_UPDATE_CHECK") != "1" || envValue(main, "FOO") != "bar" {
+		t.Errorf("expected the runner env without overriding the server env, got %+v", main.Env)
+	}
+	if len(server.Spec.Deployment.Args) != 2 {
+		t.Errorf("expected the MCPServer to be left unchanged, got args %v", server.Spec.Deployment.Args)
+	}
+}
+
+func TestRunnerPackageCache(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.Deployment.Cmd = "bunx"
+	server.Spec.Deployment.PackageCache = &v1alpha1.PackageCache{}
+	if err := CheckPackageCache(server, BuiltinDefaults()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	main := findContainer(findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec.Containers, "mcp-server")
+	if envValue(main, "BUN_INSTALL_CACHE_DIR") != "/kmcp-cache/bun" || envValue(main, "npm_config_cache") != "" {
+		t.Errorf("expected the bun cache on the cache volume, got %+v", main.Env)
+	}
+
+	// bunx has no install command
+	server.Spec.Deployment.PackageCache.PreInstall = true
+	if err := CheckPackageCache(server, BuiltinDefaults()); err == nil {
+		t.Errorf("expected pre-installation to be rejected for bunx")
+	}
+	// A command without a runner has no cache
+	server.Spec.Deployment.Cmd = "python"
+	server.Spec.Deployment.PackageCache.PreInstall = false
+	if err := CheckPackageCache(server, BuiltinDefaults()); err == nil {
+		t.Errorf("expected the package cache to be rejected for a command without a runner")
+	}
+}
+
+func TestCheckRunner(t *testing.T) {
+	tests := []struct {
+		name    string
+		runner  Runner
+		wantErr bool
+	}{
+		{
+			name:   "image only",
+			runner: Runner{Name: "docker-mcp", Image: "registry.example.com/mcp:1"},
+		},
+		{
+			name:    "missing image",
+			runner:  Runner{Name: "pipx"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown ecosystem",
+			runner:  Runner{Name: "gem", Image: "ruby:3", Ecosystem: "rubygems"},
+			wantErr: true,
+		},
+		{
+			name: "install command without package",
+			runner: Runner{
+				Name: "pipx", Image: "pipx:1", Ecosystem: PackageEcosystemPyPI,
+				InstallCommand: []string{"pipx", "install"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckRunner(tt.runner); (err != nil) != tt.wantErr {
+				t.Errorf("CheckRunner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
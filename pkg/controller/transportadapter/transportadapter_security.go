@@ -0,0 +1,347 @@
+package transportadapter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+const (
+	// DefaultSandboxRuntimeClassName is the runtime class of the sandboxed security profile.
+	DefaultSandboxRuntimeClassName = "gvisor"
+
+	// nonRootID is the user and group the restricted profile runs containers as when the
+	// deployment does not select one.
+	nonRootID = 65532
+
+	securityHomeDir = "/home/kmcp"
+)
+
+// baselineCapabilities are the capabilities the baseline pod security standard allows to add.
+var baselineCapabilities = map[corev1.Capability]bool{
+	"AUDIT_WRITE":      true,
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"MKNOD":            true,
+	"NET_BIND_SERVICE": true,
+	"SETFCAP":          true,
+	"SETGID":           true,
+	"SETPCAP":          true,
+	"SETUID":           true,
+	"SYS_CHROOT":       true,
+}
+
+// SecurityProfileOf returns the security profile of the MCPServer, falling back to the
+// default profile.
+func SecurityProfileOf(server *v1alpha1.MCPServer, defaultProfile v1alpha1.SecurityProfile) v1alpha1.SecurityProfile {
+	if server.Spec.SecurityProfile != "" {
+		return server.Spec.SecurityProfile
+	}
+	return defaultProfile
+}
+
+// CheckSecurityProfile checks that the security contexts supplied on the MCPServer, including
+// those of its pod template overlay, do not violate its security profile, and explains the
+// violations.
+func CheckSecurityProfile(server *v1alpha1.MCPServer, profile v1alpha1.SecurityProfile) error {
+	if profile == "" {
+		return nil
+	}
+	switch profile {
+	case v1alpha1.SecurityProfileBaseline, v1alpha1.SecurityProfileRestricted, v1alpha1.SecurityProfileSandboxed:
+	default:
+		return fmt.Errorf("unknown security profile %q", profile)
+	}
+
+	var violations []string
+	if podContext := server.Spec.Deployment.PodSecurityContext; podContext != nil {
+		for _, violation := range checkPodSecurityContext(profile, podContext) {
+			violations = append(violations, "deployment.podSecurityContext: "+violation)
+		}
+	}
+	overlayViolations, err := checkPodTemplateOverlay(server, profile)
+	if err != nil {
+		return err
+	}
+	violations = append(violations, overlayViolations...)
+
+	containerContexts := map[string]*corev1.SecurityContext{
+		"deployment.securityContext": server.Spec.Deployment.SecurityContext,
+	}
+	if initContainer := server.Spec.Deployment.InitContainer; initContainer != nil {
+		containerContexts["deployment.initContainer.securityContext"] = initContainer.SecurityContext
+	}
+	for _, sidecar := range server.Spec.Deployment.Sidecars {
+		containerContexts[fmt.Sprintf("deployment.sidecars[%s].securityContext", sidecar.Name)] = sidecar.SecurityContext
+	}
+	for _, field := range sortedKeys(containerContexts) {
+		if securityContext := containerContexts[field]; securityContext != nil {
+			for _, violation := range checkContainerSecurityContext(profile, securityContext) {
+				violations = append(violations, field+": "+violation)
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("security profile %s is violated: %s", profile, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// checkPodTemplateOverlay checks the pod template overlay, which takes precedence over the
+// settings of the security profile and so must not weaken them.
+func checkPodTemplateOverlay(server *v1alpha1.MCPServer, profile v1alpha1.SecurityProfile) ([]string, error) {
+	overlay, err := podTemplateOverlay(server)
+	if err != nil || overlay == nil {
+		return nil, err
+	}
+
+	const field = "deployment.podTemplate.spec"
+	var violations []string
+	spec := overlay.Spec
+	if spec.HostNetwork || spec.HostPID || spec.HostIPC {
+		violations = append(violations, field+": host namespaces give the pod access to the host")
+	}
+	if spec.SecurityContext != nil {
+		for _, violation := range checkPodSecurityContext(profile, spec.SecurityContext) {
+			violations = append(violations, field+".securityContext: "+violation)
+		}
+	}
+	if profile == v1alpha1.SecurityProfileSandboxed && spec.RuntimeClassName != nil {
+		violations = append(violations, field+".runtimeClassName: the sandboxed profile selects the runtime class")
+	}
+	for kind, containers := range map[string][]corev1.Container{
+		"containers":     spec.Containers,
+		"initContainers": spec.InitContainers,
+	} {
+		for _, container := range containers {
+			if container.SecurityContext == nil {
+				continue
+			}
+			for _, violation := range checkContainerSecurityContext(profile, container.SecurityContext) {
+				violations = append(violations, fmt.Sprintf("%s.%s[%s].securityContext: %s",
+					field, kind, container.Name, violation))
+			}
+		}
+	}
+	sort.Strings(violations)
+	return violations, nil
+}
+
+func checkPodSecurityContext(profile v1alpha1.SecurityProfile, podContext *corev1.PodSecurityContext) []string {
+	var violations []string
+	if isUnconfined(podContext.SeccompProfile) {
+		violations = append(violations, "an Unconfined seccomp profile disables syscall filtering")
+	}
+	if profile != v1alpha1.SecurityProfileBaseline {
+		if podContext.RunAsNonRoot != nil && !*podContext.RunAsNonRoot {
+			violations = append(violations, "runAsNonRoot must not be false")
+		}
+		if podContext.RunAsUser != nil && *podContext.RunAsUser == 0 {
+			violations = append(violations, "runAsUser must not be 0 (root)")
+		}
+	}
+	return violations
+}
+
+func checkContainerSecurityContext(profile v1alpha1.SecurityProfile, securityContext *corev1.SecurityContext) []string {
+	var violations []string
+	if securityContext.Privileged != nil && *securityContext.Privileged {
+		violations = append(violations, "privileged containers have full access to the host")
+	}
+	if securityContext.AllowPrivilegeEscalation != nil && *securityContext.AllowPrivilegeEscalation {
+		violations = append(violations, "allowPrivilegeEscalation lets processes gain more privileges than their parent")
+	}
+	if isUnconfined(securityContext.SeccompProfile) {
+		violations = append(violations, "an Unconfined seccomp profile disables syscall filtering")
+	}
+	if securityContext.Capabilities != nil {
+		for _, capability := range securityContext.Capabilities.Add {
+			switch {
+			case profile == v1alpha1.SecurityProfileBaseline && !baselineCapabilities[capability]:
+				violations = append(violations, fmt.Sprintf("capability %s is not allowed by the baseline profile", capability))
+			case profile != v1alpha1.SecurityProfileBaseline && capability != "NET_BIND_SERVICE":
+				violations = append(violations,
+					fmt.Sprintf("only NET_BIND_SERVICE may be added when all capabilities are dropped, not %s", capability))
+			}
+		}
+	}
+	if profile != v1alpha1.SecurityProfileBaseline {
+		if securityContext.RunAsNonRoot != nil && !*securityContext.RunAsNonRoot {
+			violations = append(violations, "runAsNonRoot must not be false")
+		}
+		if securityContext.RunAsUser != nil && *securityContext.RunAsUser == 0 {
+			violations = append(violations, "runAsUser must not be 0 (root)")
+		}
+		if securityContext.ReadOnlyRootFilesystem != nil && !*securityContext.ReadOnlyRootFilesystem {
+			violations = append(violations,
+				"readOnlyRootFilesystem must not be false, mount a volume for the paths that must be writable")
+		}
+	}
+	return violations
+}
+
+func isUnconfined(seccompProfile *corev1.SeccompProfile) bool {
+	return seccompProfile != nil && seccompProfile.Type == corev1.SeccompProfileTypeUnconfined
+}
+
+// applySecurityProfile fills the security settings of the pod that are not set with the
+// values of the security profile. Settings supplied on the MCPServer are kept; they are
+// checked against the profile by CheckSecurityProfile. It is applied after the pod
+// template overlay, so that the overlay cannot remove the settings of the profile.
+func applySecurityProfile(
+	profile v1alpha1.SecurityProfile,
+	sandboxRuntimeClassName string,
+	podSpec *corev1.PodSpec,
+) {
+	if profile == "" {
+		return
+	}
+	restricted := profile != v1alpha1.SecurityProfileBaseline
+
+	// The security contexts may be shared with the MCPServer spec
+	podContext := podSpec.SecurityContext.DeepCopy()
+	if podContext == nil {
+		podContext = &corev1.PodSecurityContext{}
+	}
+	podSpec.SecurityContext = podContext
+	if podContext.SeccompProfile == nil {
+		podContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+	if restricted {
+		if podContext.RunAsNonRoot == nil {
+			podContext.RunAsNonRoot = makePtr(true)
+		}
+		if podContext.RunAsUser == nil {
+			podContext.RunAsUser = makePtr(int64(nonRootID))
+		}
+		if podContext.RunAsGroup == nil {
+			podContext.RunAsGroup = makePtr(int64(nonRootID))
+		}
+		if podContext.FSGroup == nil {
+			podContext.FSGroup = makePtr(int64(nonRootID))
+		}
+	}
+
+	for i := range podSpec.InitContainers {
+		hardenContainer(restricted, &podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		hardenContainer(restricted, &podSpec.Containers[i])
+	}
+	if restricted {
+		addWritableDirs(podSpec)
+	}
+
+	if profile == v1alpha1.SecurityProfileSandboxed && podSpec.RuntimeClassName == nil {
+		if sandboxRuntimeClassName == "" {
+			sandboxRuntimeClassName = DefaultSandboxRuntimeClassName
+		}
+		podSpec.RuntimeClassName = makePtr(sandboxRuntimeClassName)
+	}
+}
+
+func hardenContainer(restricted bool, container *corev1.Container) {
+	securityContext := container.SecurityContext.DeepCopy()
+	if securityContext == nil {
+		securityContext = &corev1.SecurityContext{}
+	}
+	container.SecurityContext = securityContext
+	if securityContext.Privileged == nil {
+		securityContext.Privileged = makePtr(false)
+	}
+	if securityContext.AllowPrivilegeEscalation == nil {
+		securityContext.AllowPrivilegeEscalation = makePtr(false)
+	}
+	if !restricted {
+		return
+	}
+	if securityContext.Capabilities == nil {
+		securityContext.Capabilities = &corev1.Capabilities{}
+	}
+	if len(securityContext.Capabilities.Drop) == 0 {
+		securityContext.Capabilities.Drop = []corev1.Capability{"ALL"}
+	}
+	if securityContext.ReadOnlyRootFilesystem == nil {
+		securityContext.ReadOnlyRootFilesystem = makePtr(true)
+	}
+}
+
+// addWritableDirs mounts emptyDirs at /tmp and at the home directory of the containers
+// generated for the MCP server, so that npx and uvx can populate their caches on a
+// read-only root filesystem. Paths that are already mounted are left alone.
+func addWritableDirs(podSpec *corev1.PodSpec) {
+	writableDirs := []corev1.VolumeMount{
+		{Name: "kmcp-tmp", MountPath: "/tmp"},
+		{Name: "kmcp-home", MountPath: securityHomeDir},
+	}
+	cacheEnv := []corev1.EnvVar{
+		{Name: "HOME", Value: securityHomeDir},
+		{Name: "npm_config_cache", Value: securityHomeDir + "/.npm"},
+		{Name: "UV_CACHE_DIR", Value: securityHomeDir + "/.cache/uv"},
+		{Name: "XDG_CACHE_HOME", Value: securityHomeDir + "/.cache"},
+	}
+
+	used := map[string]bool{}
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name != "mcp-server" && container.Name != "transport-adapter" {
+			continue
+		}
+		for _, mount := range writableDirs {
+			if hasMountPath(container, mount.MountPath) {
+				continue
+			}
+			container.VolumeMounts = append(container.VolumeMounts, mount)
+			used[mount.Name] = true
+		}
+		for _, envVar := range cacheEnv {
+			if !hasEnvVar(container, envVar.Name) {
+				container.Env = append(container.Env, envVar)
+			}
+		}
+	}
+
+	for _, mount := range writableDirs {
+		if used[mount.Name] {
+			podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+				Name:         mount.Name,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			})
+		}
+	}
+}
+
+func hasMountPath(container *corev1.Container, path string) bool {
+	for _, mount := range container.VolumeMounts {
+		if mount.MountPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEnvVar(container *corev1.Container, name string) bool {
+	for _, envVar := range container.Env {
+		if envVar.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
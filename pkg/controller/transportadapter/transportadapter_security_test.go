@@ -0,0 +1,155 @@
+package transportadapter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func TestRestrictedSecurityProfile(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Spec.SecurityProfile = v1alpha1.SecurityProfileRestricted
+	server.Spec.Deployment.SecurityContext = &corev1.SecurityContext{RunAsUser: makePtr(int64(1000))}
+
+	spec := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec
+	if spec.SecurityContext == nil || !*spec.SecurityContext.RunAsNonRoot ||
+		spec.SecurityContext.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Errorf("expected a non-root pod with the RuntimeDefault seccomp profile, got %+v", spec.SecurityContext)
+	}
+
+	main := findContainer(spec.Containers, "mcp-server")
+	securityContext := main.SecurityContext
+	if *securityContext.RunAsUser != 1000 {
+		t.Errorf("expected the user supplied runAsUser to be kept, got %d", *securityContext.RunAsUser)
+	}
+	if !*securityContext.ReadOnlyRootFilesystem || *securityContext.AllowPrivilegeEscalation ||
+		securityContext.Capabilities.Drop[0] != "ALL" {
+		t.Errorf("expected a hardened container, got %+v", securityContext)
+	}
+	if server.Spec.Deployment.SecurityContext.ReadOnlyRootFilesystem != nil {
+		t.Errorf("expected the MCPServer spec not to be modified")
+	}
+	if !hasVolumeMount(main, "kmcp-tmp") || !hasVolumeMount(main, "kmcp-home") || !hasEnvVar(main, "npm_config_cache") {
+		t.Errorf("expected writable directories for the npm and uv caches")
+	}
+
+	initContainer := findContainer(spec.InitContainers, "copy-binary")
+	if initContainer.SecurityContext == nil || !*initContainer.SecurityContext.ReadOnlyRootFilesystem {
+		t.Errorf("expected the init container to be hardened, got %+v", initContainer.SecurityContext)
+	}
+	if spec.RuntimeClassName != nil {
+		t.Errorf("expected no runtime class for the restricted profile")
+	}
+}
+
+func TestSandboxedSecurityProfileDefault(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	translator := NewTransportAdapterTranslator(
+		newTestScheme(t),
+		nil,
+		WithSecurityProfile(v1alpha1.SecurityProfileSandboxed, ""),
+	)
+	outputs, err := translator.TranslateTransportAdapterOutputs(context.Background(), server)
+	if err != nil {
+		t.Fatalf("failed to translate outputs: %v", err)
+	}
+
+	spec := findDeployment(t, outputs).Spec.Template.Spec
+	if spec.RuntimeClassName == nil || *spec.RuntimeClassName != DefaultSandboxRuntimeClassName {
+		t.Errorf("expected the sandbox runtime class, got %v", spec.RuntimeClassName)
+	}
+	adapter := findContainer(spec.Containers, "transport-adapter")
+	if adapter.SecurityContext == nil || !*adapter.SecurityContext.ReadOnlyRootFilesystem {
+		t.Errorf("expected the adapter sidecar to be hardened, got %+v", adapter.SecurityContext)
+	}
+}
+
+func TestSecurityProfileViolations(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   v1alpha1.SecurityProfile
+		mutate    func(*v1alpha1.MCPServer)
+		violation string
+	}{
+		{
+			name:    "privileged container",
+			profile: v1alpha1.SecurityProfileBaseline,
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.SecurityContext = &corev1.SecurityContext{Privileged: makePtr(true)}
+			},
+			violation: "deployment.securityContext: privileged",
+		},
+		{
+			name:    "capability outside the baseline set",
+			profile: v1alpha1.SecurityProfileBaseline,
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.SecurityContext = &corev1.SecurityContext{
+					Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_ADMIN"}},
+				}
+			},
+			violation: "capability SYS_ADMIN",
+		},
+		{
+			name:    "root user",
+			profile: v1alpha1.SecurityProfileRestricted,
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.PodSecurityContext = &corev1.PodSecurityContext{RunAsUser: makePtr(int64(0))}
+			},
+			violation: "runAsUser must not be 0",
+		},
+		{
+			name:    "writable root filesystem in a sidecar",
+			profile: v1alpha1.SecurityProfileSandboxed,
+			mutate: func(server *v1alpha1.MCPServer) {
+				server.Spec.Deployment.Sidecars = []corev1.Container{{
+					Name:            "shipper",
+					Image:           "fluent-bit",
+					SecurityContext: &corev1.SecurityContext{ReadOnlyRootFilesystem: makePtr(false)},
+				}}
+			},
+			violation: "deployment.sidecars[shipper].securityContext: readOnlyRootFilesystem",
+		},
+		{
+			name:    "privileged container in the pod template overlay",
+			profile: v1alpha1.SecurityProfileBaseline,
+			mutate: func(server *v1alpha1.MCPServer) {
+				withPodTemplate(server,
+					`{"spec": {"containers": [{"name": "mcp-server", "securityContext": {"privileged": true}}]}}`)
+			},
+			violation: "deployment.podTemplate.spec.containers[mcp-server].securityContext: privileged",
+		},
+		{
+			name:    "root user in the pod template overlay",
+			profile: v1alpha1.SecurityProfileRestricted,
+			mutate: func(server *v1alpha1.MCPServer) {
+				withPodTemplate(server, `{"spec": {"securityContext": {"runAsUser": 0}}}`)
+			},
+			violation: "deployment.podTemplate.spec.securityContext: runAsUser must not be 0",
+		},
+		{
+			name:    "runtime class in the pod template overlay",
+			profile: v1alpha1.SecurityProfileSandboxed,
+			mutate: func(server *v1alpha1.MCPServer) {
+				withPodTemplate(server, `{"spec": {"runtimeClassName": "runc"}}`)
+			},
+			violation: "deployment.podTemplate.spec.runtimeClassName",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+			server.Spec.SecurityProfile = tt.profile
+			tt.mutate(server)
+
+			err := CheckSecurityProfile(server, tt.profile)
+			if err == nil || !strings.Contains(err.Error(), tt.violation) {
+				t.Errorf("expected a violation containing %q, got %v", tt.violation, err)
+			}
+		})
+	}
+}
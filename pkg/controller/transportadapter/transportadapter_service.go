@@ -0,0 +1,91 @@
+package transportadapter
+
+import (
+	"fmt"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// httpPortName is the name of the Service port serving the MCP endpoints.
+const httpPortName = "http"
+
+// CheckService checks that the Service settings of the MCPServer can be applied: the
+// additional ports need unique names and numbers that differ from the ports managed by
+// the controller, and the headless and external traffic settings need a matching type.
+func CheckService(server *v1alpha1.MCPServer) error {
+	service := server.Spec.Service
+	if service == nil {
+		return nil
+	}
+	serviceType := service.Type
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+	if service.Headless && serviceType != corev1.ServiceTypeClusterIP {
+		return fmt.Errorf("service.headless requires service.type to be 'ClusterIP'")
+	}
+	if service.ExternalTrafficPolicy != "" && serviceType == corev1.ServiceTypeClusterIP {
+		return fmt.Errorf("service.externalTrafficPolicy requires service.type to be 'NodePort' or 'LoadBalancer'")
+	}
+
+	names := map[string]bool{httpPortName: true, metricsPortName: true}
+	numbers := map[int32]bool{int32(server.Spec.Deployment.Port): true}
+	if metrics := serverMetrics(server); metrics != nil && server.Spec.TransportType == v1alpha1.TransportTypeStdio {
+		numbers[metricsPort(metrics)] = true
+	}
+	for _, port := range service.Ports {
+		if port.Name == "" {
+			return fmt.Errorf("service.ports requires a name for port %d", port.Port)
+		}
+		if names[port.Name] {
+			return fmt.Errorf("service port name %s is already used", port.Name)
+		}
+		if numbers[port.Port] {
+			return fmt.Errorf("service port %d is already used", port.Port)
+		}
+		names[port.Name] = true
+		numbers[port.Port] = true
+	}
+	return nil
+}
+
+// applyServiceSettings applies the Service settings of the MCPServer to its Service. The
+// labels selecting the pods of the MCP server take precedence over the custom labels.
+func applyServiceSettings(server *v1alpha1.MCPServer, service *corev1.Service) {
+	settings := server.Spec.Service
+	if settings == nil {
+		return
+	}
+
+	if len(settings.Labels) > 0 {
+		labels := maps.Clone(settings.Labels)
+		maps.Copy(labels, service.Labels)
+		service.Labels = labels
+	}
+	if len(settings.Annotations) > 0 {
+		annotations := maps.Clone(settings.Annotations)
+		maps.Copy(annotations, service.Annotations)
+		service.Annotations = annotations
+	}
+
+	service.Spec.Type = settings.Type
+	if settings.Headless {
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+	service.Spec.ExternalTrafficPolicy = settings.ExternalTrafficPolicy
+	if settings.AppProtocol != nil {
+		service.Spec.Ports[0].AppProtocol = nil
+		if *settings.AppProtocol != "" {
+			service.Spec.Ports[0].AppProtocol = makePtr(*settings.AppProtocol)
+		}
+	}
+	for _, port := range settings.Ports {
+		if port.Protocol == "" {
+			port.Protocol = corev1.ProtocolTCP
+		}
+		service.Spec.Ports = append(service.Spec.Ports, port)
+	}
+}
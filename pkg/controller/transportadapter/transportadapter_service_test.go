@@ -0,0 +1,141 @@
+package transportadapter
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func findService(t *testing.T, outputs []client.Object) *corev1.Service {
+	t.Helper()
+	for _, output := range outputs {
+		if service, ok := output.(*corev1.Service); ok {
+			return service
+		}
+	}
+	t.Fatalf("no service found in outputs")
+	return nil
+}
+
+func TestServiceSettings(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.Service = &v1alpha1.MCPServerService{
+		Type:                  corev1.ServiceTypeLoadBalancer,
+		Annotations:           map[string]string{"service.beta.kubernetes.io/aws-load-balancer-scheme": "internal"},
+		Labels:                map[string]string{"team": "platform", "app.kubernetes.io/name": "override"},
+		ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyLocal,
+		AppProtocol:           makePtr("http"),
+		Ports: []corev1.ServicePort{{
+			Name:       "debug",
+			Port:       9229,
+			TargetPort: intstr.FromInt32(9229),
+		}},
+	}
+
+	service := findService(t, translateOutputs(t, server))
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer ||
+		service.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyLocal {
+		t.Errorf("unexpected service spec: %+v", service.Spec)
+	}
+	if service.Annotations["service.beta.kubernetes.io/aws-load-balancer-scheme"] != "internal" {
+		t.Errorf("expected the annotations to be applied, got %v", service.Annotations)
+	}
+	if service.Labels["team"] != "platform" {
+		t.Errorf("expected the labels to be applied, got %v", service.Labels)
+	}
+	for key, value := range service.Spec.Selector {
+		if service.Labels[key] != value {
+			t.Errorf("expected the selector label %s to keep its value %s, got %s", key, value, service.Labels[key])
+		}
+	}
+	ports := service.Spec.Ports
+	if len(ports) != 2 || ports[0].AppProtocol == nil || *ports[0].AppProtocol != "http" {
+		t.Fatalf("expected the appProtocol of the http port to be overridden, got %+v", ports)
+	}
+	if ports[1].Name != "debug" || ports[1].Port != 9229 || ports[1].Protocol != corev1.ProtocolTCP {
+		t.Errorf("expected the additional port to be exposed, got %+v", ports[1])
+	}
+}
+
+func TestServiceHeadlessWithoutAppProtocol(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.Service = &v1alpha1.MCPServerService{
+		Headless:    true,
+		AppProtocol: makePtr(""),
+	}
+
+	service := findService(t, translateOutputs(t, server))
+	if service.Spec.ClusterIP != corev1.ClusterIPNone {
+		t.Errorf("expected a headless service, got cluster IP %q", service.Spec.ClusterIP)
+	}
+	if service.Spec.Ports[0].AppProtocol != nil {
+		t.Errorf("expected the appProtocol to be removed, got %q", *service.Spec.Ports[0].AppProtocol)
+	}
+}
+
+func TestServiceAnnotationsMerged(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.Service = &v1alpha1.MCPServerService{
+		Annotations: map[string]string{"team": "platform"},
+	}
+	service := &corev1.Service{}
+	service.Annotations = map[string]string{"kmcp.kagent.dev/spec-hash": "abc"}
+	service.Spec.Ports = []corev1.ServicePort{{Name: httpPortName}}
+
+	applyServiceSettings(server, service)
+	if service.Annotations["team"] != "platform" || service.Annotations["kmcp.kagent.dev/spec-hash"] != "abc" {
+		t.Errorf("expected the annotations to be merged, got %v", service.Annotations)
+	}
+}
+
+func TestCheckService(t *testing.T) {
+	tests := []struct {
+		name    string
+		service v1alpha1.MCPServerService
+		wantErr bool
+	}{
+		{
+			name:    "node port with local traffic policy",
+			service: v1alpha1.MCPServerService{Type: corev1.ServiceTypeNodePort, ExternalTrafficPolicy: "Local"},
+		},
+		{
+			name:    "traffic policy on cluster IP",
+			service: v1alpha1.MCPServerService{ExternalTrafficPolicy: "Local"},
+			wantErr: true,
+		},
+		{
+			name:    "headless load balancer",
+			service: v1alpha1.MCPServerService{Type: corev1.ServiceTypeLoadBalancer, Headless: true},
+			wantErr: true,
+		},
+		{
+			name:    "unnamed port",
+			service: v1alpha1.MCPServerService{Ports: []corev1.ServicePort{{Port: 9229}}},
+			wantErr: true,
+		},
+		{
+			name:    "port named http",
+			service: v1alpha1.MCPServerService{Ports: []corev1.ServicePort{{Name: "http", Port: 9229}}},
+			wantErr: true,
+		},
+		{
+			name:    "port number of the MCP server",
+			service: v1alpha1.MCPServerService{Ports: []corev1.ServicePort{{Name: "debug", Port: 3000}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+			server.Spec.Deployment.Port = 3000
+			server.Spec.Service = &tt.service
+			if err := CheckService(server); (err != nil) != tt.wantErr {
+				t.Errorf("CheckService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package transportadapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+// defaultServerPort is the CRD default of deployment.port, which the API server does not
+// apply to the schemaless spec of a template.
+const defaultServerPort = 3000
+
+// paramRefRegex matches the $(params.<name>) parameter references of a template.
+var paramRefRegex = regexp.MustCompile(`\$\(params\.([a-zA-Z_][a-zA-Z0-9_-]*)\)`)
+
+// ExpandServerTemplate returns a copy of the MCPServer whose spec is the spec of the
+// MCPServerTemplate expanded with the parameters of the MCPServer, and whose status
+// records the generation of the template. className, the rollback settings and
+// toolPinning are kept from the MCPServer when set.
+func ExpandServerTemplate(
+	server *v1alpha1.MCPServer,
+	template *v1alpha1.MCPServerTemplate,
+) (*v1alpha1.MCPServer, error) {
+	params, err := resolveTemplateParameters(server, template)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, match := range paramRefRegex.FindAllSubmatch(template.Spec.Template.Raw, -1) {
+		if _, ok := params[string(match[1])]; !ok {
+			return nil, fmt.Errorf("MCPServerTemplate %s references undeclared parameter %s", template.Name, match[1])
+		}
+	}
+
+	var spec interface{}
+	if err := json.Unmarshal(template.Spec.Template.Raw, &spec); err != nil {
+		return nil, fmt.Errorf("invalid template of MCPServerTemplate %s: %w", template.Name, err)
+	}
+	spec = substituteParameters(spec, reflect.TypeOf(v1alpha1.MCPServerSpec{}), params)
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the expanded template of MCPServerTemplate %s: %w", template.Name, err)
+	}
+
+	expanded := server.DeepCopy()
+	expanded.Spec = v1alpha1.MCPServerSpec{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&expanded.Spec); err != nil {
+		return nil, fmt.Errorf("invalid template of MCPServerTemplate %s: %w", template.Name, err)
+	}
+	if expanded.Spec.Deployment.Port == 0 {
+		expanded.Spec.Deployment.Port = defaultServerPort
+	}
+	if server.Spec.ClassName != "" {
+		expanded.Spec.ClassName = server.Spec.ClassName
+	}
+	expanded.Spec.TemplateRef = server.Spec.TemplateRef
+	expanded.Spec.Parameters = server.Spec.Parameters
+	if server.Spec.RollbackOnFailure != nil {
+		expanded.Spec.RollbackOnFailure = server.Spec.RollbackOnFailure
+	}
+	if server.Spec.RevisionHistoryLimit != nil {
+		expanded.Spec.RevisionHistoryLimit = server.Spec.RevisionHistoryLimit
+	}
+	if server.Spec.ToolPinning != nil {
+		expanded.Spec.ToolPinning = server.Spec.ToolPinning
+	}
+	expanded.Status.TemplateGeneration = template.Generation
+	return expanded, nil
+}
+
+// resolveTemplateParameters returns the typed values of the parameters of the template,
+// taken from the MCPServer or from their defaults.
+func resolveTemplateParameters(
+	server *v1alpha1.MCPServer,
+	template *v1alpha1.MCPServerTemplate,
+) (map[string]interface{}, error) {
+	declared := make(map[string]bool, len(template.Spec.Parameters))
+	params := make(map[string]interface{}, len(template.Spec.Parameters))
+	for _, param := range template.Spec.Parameters {
+		declared[param.Name] = true
+		value, ok := server.Spec.Parameters[param.Name]
+		if !ok {
+			if param.Default == nil {
+				return nil, fmt.Errorf("parameter %s of MCPServerTemplate %s is required", param.Name, template.Name)
+			}
+			value = *param.Default
+		}
+		typed, err := typedParameterValue(param.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s of MCPServerTemplate %s: %w", param.Name, template.Name, err)
+		}
+		params[param.Name] = typed
+	}
+
+	for _, name := range sortedKeys(server.Spec.Parameters) {
+		if !declared[name] {
+			return nil, fmt.Errorf("parameter %s is not declared by MCPServerTemplate %s", name, template.Name)
+		}
+	}
+	return params, nil
+}
+
+func typedParameterValue(paramType v1alpha1.TemplateParameterType, value string) (interface{}, error) {
+	switch paramType {
+	case v1alpha1.TemplateParameterTypeInteger:
+		integer, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return integer, nil
+	case v1alpha1.TemplateParameterTypeBoolean:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return boolean, nil
+	case v1alpha1.TemplateParameterTypeString, "":
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unknown parameter type %q", paramType)
+	}
+}
+
+// substituteParameters replaces the parameter references in the string values of the
+// decoded JSON document, whose Go type is target. A string made up of a single reference
+// is replaced by the typed value of the parameter, unless the field it sets is a string;
+// references within longer strings are replaced by the text of the value.
+func substituteParameters(value interface{}, target reflect.Type, params map[string]interface{}) interface{} {
+	for target != nil && target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = substituteParameters(item, fieldType(target, key), params)
+		}
+		return v
+	case []interface{}:
+		var elem reflect.Type
+		if target != nil && (target.Kind() == reflect.Slice || target.Kind() == reflect.Array) {
+			elem = target.Elem()
+		}
+		for i, item := range v {
+			v[i] = substituteParameters(item, elem, params)
+		}
+		return v
+	case string:
+		match := paramRefRegex.FindStringSubmatch(v)
+		if match != nil && match[0] == v && (target == nil || target.Kind() != reflect.String) {
+			return params[match[1]]
+		}
+		return paramRefRegex.ReplaceAllStringFunc(v, func(ref string) string {
+			name := strings.TrimSuffix(strings.TrimPrefix(ref, "$(params."), ")")
+			return fmt.Sprint(params[name])
+		})
+	default:
+		return v
+	}
+}
+
+// fieldType returns the Go type of the value stored under key in a JSON object of type
+// target: the element type of a map, or the type of the struct field with that JSON name.
+// It returns nil when the type is unknown.
+func fieldType(target reflect.Type, key string) reflect.Type {
+	if target == nil {
+		return nil
+	}
+	switch target.Kind() {
+	case reflect.Map:
+		return target.Elem()
+	case reflect.Struct:
+		for i := 0; i < target.NumField(); i++ {
+			field := target.Field(i)
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == key {
+				return field.Type
+			}
+			if name == "" && (field.Anonymous || strings.Contains(opts, "inline")) {
+				if inlined := fieldType(field.Type, key); inlined != nil {
+					return inlined
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package transportadapter
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func newTestTemplate() *v1alpha1.MCPServerTemplate {
+	return &v1alpha1.MCPServerTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "github", Generation: 3},
+		Spec: v1alpha1.MCPServerTemplateSpec{
+			Parameters: []v1alpha1.TemplateParameter{
+				{Name: "secret", Type: v1alpha1.TemplateParameterTypeString},
+				{Name: "toolsets", Default: makePtr("repos,issues")},
+				{Name: "replicas", Type: v1alpha1.TemplateParameterTypeInteger, Default: makePtr("1")},
+				{Name: "probesDisabled", Type: v1alpha1.TemplateParameterTypeBoolean, Default: makePtr("false")},
+			},
+			Template: runtime.RawExtension{Raw: []byte(`{
+				"transportType": "stdio",
+				"deployment": {
+					"image": "ghcr.io/github/github-mcp-server:v0.9.0",
+					"args": ["stdio", "--toolsets=$(params.toolsets)"],
+					"secretRefs": [{"name": "$(params.secret)"}],
+					"replicas": "$(params.replicas)",
+					"env": {"PROBES_DISABLED": "$(params.probesDisabled)"},
+					"probes": {"disabled": "$(params.probesDisabled)"}
+				}
+			}`)},
+		},
+	}
+}
+
+func newTestInstance(params map[string]string) *v1alpha1.MCPServer {
+	return &v1alpha1.MCPServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: "team-a"},
+		Spec: v1alpha1.MCPServerSpec{
+			ClassName:   "standard",
+			TemplateRef: &v1alpha1.MCPServerTemplateReference{Name: "github"},
+			Parameters:  params,
+		},
+	}
+}
+
+func TestExpandServerTemplate(t *testing.T) {
+	expanded, err := ExpandServerTemplate(newTestInstance(map[string]string{
+		"secret":         "team-a-github",
+		"replicas":       "2",
+		"probesDisabled": "true",
+	}), newTestTemplate())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deployment := expanded.Spec.Deployment
+	if deployment.Args[1] != "--toolsets=repos,issues" {
+		t.Errorf("expected the default of the parameter within the argument, got %q", deployment.Args[1])
+	}
+	if deployment.SecretRefs[0].Name != "team-a-github" {
+		t.Errorf("expected the secret parameter, got %q", deployment.SecretRefs[0].Name)
+	}
+	if deployment.Replicas == nil || *deployment.Replicas != 2 {
+		t.Errorf("expected the integer parameter to set the replicas, got %v", deployment.Replicas)
+	}
+	if deployment.Probes == nil || !deployment.Probes.Disabled {
+		t.Errorf("expected the boolean parameter to disable the probes, got %+v", deployment.Probes)
+	}
+	if deployment.Env["PROBES_DISABLED"] != "true" {
+		t.Errorf("expected the text of the boolean parameter in a string field, got %q", deployment.Env["PROBES_DISABLED"])
+	}
+	if deployment.Port != 3000 {
+		t.Errorf("expected the default port, got %d", deployment.Port)
+	}
+	if expanded.Spec.ClassName != "standard" || expanded.Spec.TemplateRef.Name != "github" {
+		t.Errorf("expected the class and the template reference of the instance to be kept, got %+v", expanded.Spec)
+	}
+	if expanded.Status.TemplateGeneration != 3 {
+		t.Errorf("expected the template generation in the status, got %d", expanded.Status.TemplateGeneration)
+	}
+}
+
+func TestExpandServerTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		mutate func(*v1alpha1.MCPServerTemplate)
+		err    string
+	}{
+		{
+			name:   "missing required parameter",
+			params: map[string]string{},
+			err:    "parameter secret of MCPServerTemplate github is required",
+		},
+		{
+			name:   "undeclared parameter",
+			params: map[string]string{"secret": "s", "token": "t"},
+			err:    "parameter token is not declared",
+		},
+		{
+			name:   "mistyped parameter",
+			params: map[string]string{"secret": "s", "replicas": "two"},
+			err:    `"two" is not an integer`,
+		},
+		{
+			name:   "undeclared reference",
+			params: map[string]string{"secret": "s"},
+			mutate: func(template *v1alpha1.MCPServerTemplate) {
+				template.Spec.Template.Raw = []byte(`{"deployment": {"image": "$(params.image)"}}`)
+			},
+			err: "references undeclared parameter image",
+		},
+		{
+			name:   "unknown field",
+			params: map[string]string{"secret": "s"},
+			mutate: func(template *v1alpha1.MCPServerTemplate) {
+				template.Spec.Template.Raw = []byte(`{"stdioTransport": {"readOnly": true}}`)
+			},
+			err: `unknown field "readOnly"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := newTestTemplate()
+			if tt.mutate != nil {
+				tt.mutate(template)
+			}
+			_, err := ExpandServerTemplate(newTestInstance(tt.params), template)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
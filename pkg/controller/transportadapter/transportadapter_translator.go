@@ -4,10 +4,12 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"os"
-	"regexp"
+	"maps"
 	"sort"
+	"strconv"
+	"strings"
 
 	"go.uber.org/multierr"
 	appsv1 "k8s.io/api/apps/v1"
@@ -24,15 +26,38 @@ import (
 )
 
 const (
-	transportAdapterRepository     = "ghcr.io/agentgateway/agentgateway"
-	defaultTransportAdapterVersion = "0.9.0"
-	kgatewayMcpAppProtocol         = "kgateway.dev/mcp"
+	kgatewayMcpAppProtocol = "kgateway.dev/mcp"
+	defaultBridgeImage     = "busybox:1.37"
+	adapterBinDir          = "/adapterbin"
 )
 
-// versionRegex validates that version strings contain only allowed characters
-// (alphanumeric, dots, hyphens) to prevent potential image injection attacks
-var versionRegex = regexp.MustCompile(`^[a-zA-Z0-9.\-]+$`)
-var disableKgatewayMcpAppProtocol = os.Getenv("DISABLE_KGATEWAY_MCP_APP_PROTOCOL")
+// The exec bridge used in Sidecar adapter mode connects the stdio processes spawned
+// by the transport adapter with processes started in the MCP server container.
+// For every session the adapter creates a directory with a pair of FIFOs under
+// /bridge/sessions and marks it ready; the MCP server container picks it up and
+// runs its command with stdin and stdout connected to the FIFOs.
+const (
+	// bridgeServeScript runs in the MCP server container. The MCP server command
+	// and its arguments are passed as positional parameters.
+	bridgeServeScript = `mkdir -p /bridge/sessions
+while true; do
+  for s in /bridge/sessions/*; do
+    if [ -e "$s/ready" ]; then
+      rm -f "$s/ready"
+      ( "$@" <"$s/in" >"$s/out"; rm -rf "$s" ) &
+    fi
+  done
+  sleep 0.1
+done`
+
+	// bridgeSessionScript runs in the transport adapter sidecar container once per session.
+	bridgeSessionScript = `mkdir -p /bridge/sessions
+s=$(mktemp -d /bridge/sessions/XXXXXX)
+mkfifo "$s/in" "$s/out"
+touch "$s/ready"
+cat "$s/out" &
+exec cat >"$s/in"`
+)
 
 // Translator is the interface for translating MCPServer objects to TransportAdapter objects.
 type Translator interface {
@@ -48,25 +73,86 @@ type TranslatorPlugin func(
 	objects []client.Object,
 ) ([]client.Object, error)
 
+// TranslatorOption configures optional behavior of the transport adapter translator.
+type TranslatorOption func(*transportAdapterTranslator)
+
+// WithDefaultAdapterBackend sets the adapter backend used for stdio MCPServers that do not
+// select one. An empty name keeps the agentgateway default.
+func WithDefaultAdapterBackend(name string) TranslatorOption {
+	return func(t *transportAdapterTranslator) {
+		if name != "" {
+			t.defaultAdapterBackend = name
+		}
+	}
+}
+
+// WithMonitoring enables the generation of Prometheus Operator monitors for MCPServers
+// exposing metrics. kinds lists the monitor kinds whose CRDs are installed in the cluster;
+// labels are added to every monitor so that it is selected by the Prometheus instance.
+func WithMonitoring(labels map[string]string, kinds ...v1alpha1.MetricsMonitorKind) TranslatorOption {
+	return func(t *transportAdapterTranslator) {
+		t.monitorLabels = labels
+		t.monitorKinds = make(map[v1alpha1.MetricsMonitorKind]bool, len(kinds))
+		for _, kind := range kinds {
+			t.monitorKinds[kind] = true
+		}
+	}
+}
+
+// WithDefaults sets the images and features configured for the controller. The built-in
+// defaults are used otherwise.
+func WithDefaults(defaults Defaults) TranslatorOption {
+	return func(t *transportAdapterTranslator) {
+		t.defaults = defaults
+	}
+}
+
+// WithSecurityProfile sets the security profile applied to MCPServers that do not select
+// one and the runtime class of the sandboxed profile. An empty runtime class keeps gvisor.
+func WithSecurityProfile(defaultProfile v1alpha1.SecurityProfile, sandboxRuntimeClassName string) TranslatorOption {
+	return func(t *transportAdapterTranslator) {
+		t.defaultSecurityProfile = defaultProfile
+		t.sandboxRuntimeClassName = sandboxRuntimeClassName
+	}
+}
+
 type transportAdapterTranslator struct {
-	scheme  *runtime.Scheme
-	plugins []TranslatorPlugin
+	scheme                *runtime.Scheme
+	plugins               []TranslatorPlugin
+	defaults              Defaults
+	defaultAdapterBackend string
+	monitorLabels         map[string]string
+	monitorKinds          map[v1alpha1.MetricsMonitorKind]bool
+
+	defaultSecurityProfile  v1alpha1.SecurityProfile
+	sandboxRuntimeClassName string
 }
 
-func NewTransportAdapterTranslator(scheme *runtime.Scheme, plugins []TranslatorPlugin) Translator {
-	return &transportAdapterTranslator{
-		scheme:  scheme,
-		plugins: plugins,
+func NewTransportAdapterTranslator(
+	scheme *runtime.Scheme,
+	plugins []TranslatorPlugin,
+	opts ...TranslatorOption,
+) Translator {
+	t := &transportAdapterTranslator{
+		scheme:                scheme,
+		plugins:               plugins,
+		defaults:              BuiltinDefaults(),
+		defaultAdapterBackend: AgentgatewayBackendName,
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
 }
 
 func (t *transportAdapterTranslator) TranslateTransportAdapterOutputs(
 	ctx context.Context,
 	server *v1alpha1.MCPServer,
 ) ([]client.Object, error) {
-	deployment, err := t.translateTransportAdapterDeployment(server)
+	server = applyRunner(server, t.defaults)
+	workload, err := t.translateTransportAdapterWorkload(server)
 	if err != nil {
-		return nil, fmt.Errorf("failed to translate TransportAdapter deployment: %w", err)
+		return nil, err
 	}
 	service, err := t.translateTransportAdapterService(server)
 	if err != nil {
@@ -77,10 +163,16 @@ func (t *transportAdapterTranslator) TranslateTransportAdapterOutputs(
 		return nil, fmt.Errorf("failed to marshal MCP server config to YAML: %w", err)
 	}
 
+	bindingSecret, err := t.translateBindingSecret(server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate TransportAdapter binding secret: %w", err)
+	}
+
 	objects := []client.Object{
-		deployment,
+		workload,
 		service,
 		configMap,
+		bindingSecret,
 	}
 
 	// Create new service account only when service account name is not specified
@@ -92,27 +184,133 @@ func (t *transportAdapterTranslator) TranslateTransportAdapterOutputs(
 		objects = append(objects, serviceAccount)
 	}
 
+	monitor, err := t.translateMonitor(server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate TransportAdapter monitor: %w", err)
+	}
+	if monitor != nil {
+		objects = append(objects, monitor)
+	}
+
 	return t.runPlugins(ctx, server, objects)
 }
 
+// translateTransportAdapterWorkload translates the MCPServer into the workload
+// object selected by its WorkloadKind.
+func (t *transportAdapterTranslator) translateTransportAdapterWorkload(
+	server *v1alpha1.MCPServer,
+) (client.Object, error) {
+	switch server.Spec.Deployment.WorkloadKind {
+	case v1alpha1.WorkloadKindStatefulSet:
+		statefulSet, err := t.translateTransportAdapterStatefulSet(server)
+		if err != nil {
+			return nil, fmt.Errorf("failed to translate TransportAdapter statefulset: %w", err)
+		}
+		return statefulSet, nil
+	case v1alpha1.WorkloadKindDeployment, "":
+		deployment, err := t.translateTransportAdapterDeployment(server)
+		if err != nil {
+			return nil, fmt.Errorf("failed to translate TransportAdapter deployment: %w", err)
+		}
+		return deployment, nil
+	default:
+		return nil, fmt.Errorf("unsupported workload kind: %s", server.Spec.Deployment.WorkloadKind)
+	}
+}
+
 func (t *transportAdapterTranslator) translateTransportAdapterDeployment(
 	server *v1alpha1.MCPServer,
 ) (*appsv1.Deployment, error) {
-	image := server.Spec.Deployment.Image
-	if image == "" && server.Spec.Deployment.Cmd == "uvx" {
-		image = "ghcr.io/astral-sh/uv:debian"
-		klog.Infof("MCPServer %s: Injected default image for uvx command: %s", server.Name, image)
+	podTemplate, err := t.translateTransportAdapterPodTemplate(server)
+	if err != nil {
+		return nil, err
 	}
-	if image == "" && server.Spec.Deployment.Cmd == "npx" {
-		image = "node:24-alpine3.21"
-		klog.Infof("MCPServer %s: Injected default image for npx command: %s", server.Name, image)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      server.Name,
+			Namespace: server.Namespace,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: server.Spec.Deployment.Replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorLabels(server),
+			},
+			Template: *podTemplate,
+		},
+	}
+
+	return deployment, controllerutil.SetOwnerReference(server, deployment, t.scheme)
+}
+
+func (t *transportAdapterTranslator) translateTransportAdapterStatefulSet(
+	server *v1alpha1.MCPServer,
+) (*appsv1.StatefulSet, error) {
+	podTemplate, err := t.translateTransportAdapterPodTemplate(server)
+	if err != nil {
+		return nil, err
+	}
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      server.Name,
+			Namespace: server.Namespace,
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: server.Spec.Deployment.Replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorLabels(server),
+			},
+			// The StatefulSet is governed by the Service that fronts the MCP server,
+			// which shares the MCPServer name.
+			ServiceName:          server.Name,
+			Template:             *podTemplate,
+			VolumeClaimTemplates: server.Spec.Deployment.VolumeClaimTemplates,
+		},
+	}
+
+	return statefulSet, controllerutil.SetOwnerReference(server, statefulSet, t.scheme)
+}
+
+// serverImage returns the image of the MCP server container: deployment.image, or the
+// default image of the runner of its command.
+func serverImage(server *v1alpha1.MCPServer, defaults Defaults) string {
+	if image := server.Spec.Deployment.Image; image != "" {
+		return image
+	}
+	if runner, ok := defaults.LookupRunner(server.Spec.Deployment.Cmd); ok {
+		return runner.Image
+	}
+	return ""
+}
+
+// translateTransportAdapterPodTemplate translates the MCPServer into the pod template
+// shared by all workload kinds.
+func (t *transportAdapterTranslator) translateTransportAdapterPodTemplate(
+	server *v1alpha1.MCPServer,
+) (*corev1.PodTemplateSpec, error) {
+	image := serverImage(server, t.defaults)
+	if image != server.Spec.Deployment.Image {
+		klog.Infof("MCPServer %s: Injected default image for %s command: %s", server.Name, server.Spec.Deployment.Cmd, image)
 	}
 	if image == "" {
-		return nil, fmt.Errorf("image must be specified for MCPServer %s or the command must be 'uvx' or 'npx'", server.Name)
+		return nil, fmt.Errorf("image must be specified for MCPServer %s or the command must be one of the runners %v",
+			server.Name, t.defaults.RunnerNames())
 	}
 
 	// Create environment variables from secrets for envFrom
-	secretEnvFrom := t.createSecretEnvFrom(server.Spec.Deployment.SecretRefs)
+	secretEnvFrom := append(
+		t.createSecretEnvFrom(server.Spec.Deployment.SecretRefs),
+		server.Spec.Deployment.EnvFrom...,
+	)
 
 	// Create volumes from the MCPServer spec
 	volumes := t.createVolumes(server.Spec.Deployment)
@@ -120,9 +318,27 @@ func (t *transportAdapterTranslator) translateTransportAdapterDeployment(
 	// Create volume mounts from the MCPServer spec
 	volumeMounts := t.createVolumeMounts(server.Spec.Deployment)
 
+	var backend AdapterBackend
+	if server.Spec.TransportType == v1alpha1.TransportTypeStdio {
+		var err error
+		backend, err = t.adapterBackend(server)
+		if err != nil {
+			return nil, err
+		}
+		if !backend.SupportsCopyBinary() && stdioAdapterMode(server) == v1alpha1.StdioAdapterModeCopyBinary {
+			return nil, fmt.Errorf(
+				"adapter backend %s does not support the %s adapter mode, use %s for MCPServer %s",
+				backend.Name(), v1alpha1.StdioAdapterModeCopyBinary, v1alpha1.StdioAdapterModeSidecar, server.Name,
+			)
+		}
+	}
+
 	// Determine the init container image and pull policy to use
 	// Start with the default transport adapter image
-	transportAdapterContainerImage := getTransportAdapterImage()
+	var transportAdapterContainerImage string
+	if backend != nil {
+		transportAdapterContainerImage = backend.Image(t.defaults)
+	}
 
 	initContainerPullPolicy := corev1.PullIfNotPresent
 	var initContainerResources corev1.ResourceRequirements
@@ -171,6 +387,7 @@ func (t *transportAdapterTranslator) translateTransportAdapterDeployment(
 	switch server.Spec.TransportType {
 	case v1alpha1.TransportTypeStdio:
 		// copy the binary into the container when running with stdio
+		adapterCmd, adapterArgs := backend.Command(adapterBinDir, server, t.translateStdioTarget(server))
 		template = corev1.PodSpec{
 			ServiceAccountName: serviceAccountName,
 			SecurityContext:    server.Spec.Deployment.PodSecurityContext,
@@ -183,13 +400,10 @@ func (t *transportAdapterTranslator) translateTransportAdapterDeployment(
 				Image:           transportAdapterContainerImage,
 				ImagePullPolicy: initContainerPullPolicy,
 				Command:         []string{},
-				Args: []string{
-					"--copy-self",
-					"/adapterbin/agentgateway",
-				},
+				Args:            backend.CopyArgs(adapterBinDir),
 				VolumeMounts: []corev1.VolumeMount{{
 					Name:      "binary",
-					MountPath: "/adapterbin",
+					MountPath: adapterBinDir,
 				}},
 				Resources:       initContainerResources,
 				SecurityContext: initContainerSecurityContext,
@@ -198,16 +412,11 @@ func (t *transportAdapterTranslator) translateTransportAdapterDeployment(
 				Name:            "mcp-server",
 				Image:           image,
 				ImagePullPolicy: mainContainerPullPolicy,
-				Command: []string{
-					"/adapterbin/agentgateway",
-				},
-				Args: []string{
-					"-f",
-					"/config/local.yaml",
-				},
-				Env:       convertEnvVars(server.Spec.Deployment.Env),
-				EnvFrom:   secretEnvFrom,
-				Resources: mainContainerResources,
+				Command:         adapterCmd,
+				Args:            adapterArgs,
+				Env:             translateContainerEnv(server),
+				EnvFrom:         secretEnvFrom,
+				Resources:       mainContainerResources,
 				VolumeMounts: append([]corev1.VolumeMount{
 					{
 						Name:      "config",
@@ -215,7 +424,7 @@ func (t *transportAdapterTranslator) translateTransportAdapterDeployment(
 					},
 					{
 						Name:      "binary",
-						MountPath: "/adapterbin",
+						MountPath: adapterBinDir,
 					},
 				}, volumeMounts...),
 				SecurityContext: server.Spec.Deployment.SecurityContext,
@@ -258,7 +467,7 @@ func (t *transportAdapterTranslator) translateTransportAdapterDeployment(
 					ImagePullPolicy: mainContainerPullPolicy,
 					Command:         cmd,
 					Args:            server.Spec.Deployment.Args,
-					Env:             convertEnvVars(server.Spec.Deployment.Env),
+					Env:             translateContainerEnv(server),
 					EnvFrom:         secretEnvFrom,
 					Resources:       mainContainerResources,
 					VolumeMounts: append([]corev1.VolumeMount{
@@ -293,6 +502,8 @@ func (t *transportAdapterTranslator) translateTransportAdapterDeployment(
 	for k, v := range server.Spec.Deployment.Labels {
 		podLabels[k] = v
 	}
+	// The controller follows the pods of the MCPServer by this label
+	podLabels[v1alpha1.MCPServerNameLabel] = server.Name
 
 	// Prepare pod template annotations (merge defaults with custom annotations)
 	var podAnnotations map[string]string
@@ -303,66 +514,217 @@ func (t *transportAdapterTranslator) translateTransportAdapterDeployment(
 		}
 	}
 
-	// Determine replicas (default to 1)
-	var replicas *int32
-	if server.Spec.Deployment.Replicas != nil {
-		replicas = server.Spec.Deployment.Replicas
+	if server.Spec.TransportType == v1alpha1.TransportTypeStdio &&
+		stdioAdapterMode(server) == v1alpha1.StdioAdapterModeSidecar {
+		if err := t.applyStdioSidecarMode(
+			server,
+			backend,
+			transportAdapterContainerImage,
+			initContainerPullPolicy,
+			&template,
+		); err != nil {
+			return nil, err
+		}
 	}
+	addMetricsContainerPort(server, &template)
+	applyProbes(server, backend, &template)
+	applyPackageCache(server, t.defaults, image, mainContainerPullPolicy, &template)
 
-	deployment := &appsv1.Deployment{
+	securityProfile := SecurityProfileOf(server, t.defaultSecurityProfile)
+	if err := CheckSecurityProfile(server, securityProfile); err != nil {
+		return nil, err
+	}
+
+	podTemplate := &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      server.Name,
-			Namespace: server.Namespace,
-		},
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Deployment",
-			APIVersion: appsv1.SchemeGroupVersion.String(),
-		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: replicas,
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app.kubernetes.io/name":     server.Name,
-					"app.kubernetes.io/instance": server.Name,
-				},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels:      podLabels,
-					Annotations: podAnnotations,
-				},
-				Spec: template,
-			},
+			Labels:      podLabels,
+			Annotations: podAnnotations,
 		},
+		Spec: template,
 	}
 
-	// Set owner reference first
-	if err := controllerutil.SetOwnerReference(server, deployment, t.scheme); err != nil {
+	podTemplate, err := applyPodTemplateOverlay(server, podTemplate)
+	if err != nil {
 		return nil, err
 	}
+	applySecurityProfile(securityProfile, t.sandboxRuntimeClassName, &podTemplate.Spec)
 
-	configYaml, err := t.translateTransportAdapterConfigAsYAML(server)
+	configData, err := t.translateTransportAdapterConfigData(server)
 	if err != nil {
 		return nil, err
 	}
+	// The probe settings are counted in the hash so that they are rolled out like config changes
+	if probes := server.Spec.Deployment.Probes; probes != nil {
+		probesJSON, err := json.Marshal(probes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal probes: %w", err)
+		}
+		configData = maps.Clone(configData)
+		configData["probes"] = string(probesJSON)
+	}
 	// Add hash annotation based on MCPServer spec to initiate a restart on changes to the MCPServer spec
-	t.addMCPServerConfigHashAnnotation(deployment, configYaml)
+	addMCPServerConfigHashAnnotation(podTemplate, configData)
+
+	return podTemplate, nil
+}
+
+// applyStdioSidecarMode rewires a copy-binary stdio pod spec so that the transport adapter
+// runs in its own sidecar container. The MCP server container keeps its image and runs
+// its command behind the exec bridge. When the backend supports it, the adapter binary
+// copied by the init container is executed from the bridge image; otherwise the adapter
+// runs from its own image and the init container is dropped.
+func (t *transportAdapterTranslator) applyStdioSidecarMode(
+	server *v1alpha1.MCPServer,
+	backend AdapterBackend,
+	adapterImage string,
+	adapterPullPolicy corev1.PullPolicy,
+	podSpec *corev1.PodSpec,
+) error {
+	if server.Spec.Deployment.Cmd == "" {
+		return fmt.Errorf("deployment.cmd must be specified for MCPServer %s in Sidecar adapter mode", server.Name)
+	}
+
+	bridgeImage := defaultBridgeImage
+	if server.Spec.StdioTransport.BridgeImage != "" {
+		bridgeImage = server.Spec.StdioTransport.BridgeImage
+	}
 
-	return deployment, nil
+	bridgeMount := corev1.VolumeMount{
+		Name:      "bridge",
+		MountPath: "/bridge",
+	}
+
+	// The MCP server container runs its own command behind the exec bridge
+	// instead of the copied adapter binary.
+	mainContainer := &podSpec.Containers[0]
+	mainContainer.Command = append([]string{
+		"/bin/sh",
+		"-c",
+		bridgeServeScript,
+		"kmcp-bridge",
+		server.Spec.Deployment.Cmd,
+	}, server.Spec.Deployment.Args...)
+	mainContainer.Args = nil
+	volumeMounts := make([]corev1.VolumeMount, 0, len(mainContainer.VolumeMounts))
+	for _, mount := range mainContainer.VolumeMounts {
+		if mount.Name == "binary" {
+			continue
+		}
+		volumeMounts = append(volumeMounts, mount)
+	}
+	mainContainer.VolumeMounts = append(volumeMounts, bridgeMount)
+
+	adapterContainer := corev1.Container{
+		Name:            "transport-adapter",
+		Image:           bridgeImage,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "config",
+				MountPath: "/config",
+			},
+			{
+				Name:      "binary",
+				MountPath: adapterBinDir,
+			},
+			bridgeMount,
+		},
+	}
+	target := t.translateStdioTarget(server)
+	if backend.SupportsCopyBinary() {
+		adapterContainer.Command, adapterContainer.Args = backend.Command(adapterBinDir, server, target)
+	} else {
+		adapterContainer.Image = adapterImage
+		adapterContainer.ImagePullPolicy = adapterPullPolicy
+		adapterContainer.Command, adapterContainer.Args = backend.Command("", server, target)
+		adapterContainer.VolumeMounts = []corev1.VolumeMount{
+			adapterContainer.VolumeMounts[0],
+			bridgeMount,
+		}
+
+		// Nothing to copy, the adapter runs from its own image
+		podSpec.InitContainers = nil
+		podVolumes := make([]corev1.Volume, 0, len(podSpec.Volumes))
+		for _, volume := range podSpec.Volumes {
+			if volume.Name == "binary" {
+				continue
+			}
+			podVolumes = append(podVolumes, volume)
+		}
+		podSpec.Volumes = podVolumes
+	}
+	if initContainer := server.Spec.Deployment.InitContainer; initContainer != nil {
+		if initContainer.Resources != nil {
+			adapterContainer.Resources = *initContainer.Resources
+		}
+		if initContainer.SecurityContext != nil {
+			adapterContainer.SecurityContext = initContainer.SecurityContext.DeepCopy()
+		}
+	}
+
+	// Keep the MCP server container first so that it remains the default container
+	podSpec.Containers = append(
+		[]corev1.Container{*mainContainer, adapterContainer},
+		podSpec.Containers[1:]...,
+	)
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: "bridge",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+
+	return nil
 }
 
-// addMCPServerSpecHashAnnotation adds a hash annotation to the deployment's pod template
-// based on the MCPServer config yaml. This ensures pod restarts when the MCPServer configuration changes.
-func (t *transportAdapterTranslator) addMCPServerConfigHashAnnotation(
-	deployment *appsv1.Deployment,
-	mcpServerConfigYaml string,
+// stdioAdapterMode returns the adapter mode of a stdio MCPServer, defaulting to CopyBinary.
+func stdioAdapterMode(server *v1alpha1.MCPServer) v1alpha1.StdioAdapterMode {
+	if server.Spec.StdioTransport == nil || server.Spec.StdioTransport.AdapterMode == "" {
+		return v1alpha1.StdioAdapterModeCopyBinary
+	}
+	return server.Spec.StdioTransport.AdapterMode
+}
+
+// adapterBackend returns the adapter backend selected by a stdio MCPServer, falling back
+// to the default backend of the translator.
+func (t *transportAdapterTranslator) adapterBackend(server *v1alpha1.MCPServer) (AdapterBackend, error) {
+	name := t.defaultAdapterBackend
+	if server.Spec.StdioTransport != nil && server.Spec.StdioTransport.AdapterBackend != "" {
+		name = server.Spec.StdioTransport.AdapterBackend
+	}
+	return LookupAdapterBackend(name)
+}
+
+// addMCPServerSpecHashAnnotation adds a hash annotation to the workload's pod template
+// based on the MCPServer config files. This ensures pod restarts when the MCPServer configuration changes.
+func addMCPServerConfigHashAnnotation(
+	podTemplate *corev1.PodTemplateSpec,
+	configData map[string]string,
 ) {
-	hash := sha256.Sum256([]byte(mcpServerConfigYaml))
+	// Hash the files in a stable order; a single file hashes to the hash of its content
+	keys := make([]string, 0, len(configData))
+	for key := range configData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var config strings.Builder
+	for _, key := range keys {
+		config.WriteString(configData[key])
+	}
+	hash := sha256.Sum256([]byte(config.String()))
 	truncatedHash := hex.EncodeToString(hash[:])[:8]
-	if deployment.Spec.Template.Annotations == nil {
-		deployment.Spec.Template.Annotations = make(map[string]string)
+	if podTemplate.Annotations == nil {
+		podTemplate.Annotations = make(map[string]string)
+	}
+	podTemplate.Annotations["kmcp.kagent.dev/mcpserver-config-hash"] = truncatedHash
+}
+
+// selectorLabels returns the labels used to select the pods of the MCPServer workload.
+func selectorLabels(server *v1alpha1.MCPServer) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":     server.Name,
+		"app.kubernetes.io/instance": server.Name,
 	}
-	deployment.Spec.Template.Annotations["kmcp.kagent.dev/mcpserver-config-hash"] = truncatedHash
 }
 
 func (t *transportAdapterTranslator) translateTransportAdapterServiceAccount(
@@ -472,6 +834,14 @@ func (t *transportAdapterTranslator) createVolumeMounts(
 	return volumeMounts
 }
 
+// translateContainerEnv returns the environment variables of the MCP server container:
+// the plain env values, followed by the typed envVars and the OTEL_* variables.
+func translateContainerEnv(server *v1alpha1.MCPServer) []corev1.EnvVar {
+	envVars := convertEnvVars(server.Spec.Deployment.Env)
+	envVars = append(envVars, server.Spec.Deployment.EnvVars...)
+	return append(envVars, translateTracingEnvVars(server)...)
+}
+
 func convertEnvVars(env map[string]string) []corev1.EnvVar {
 	if env == nil {
 		return nil
@@ -497,14 +867,19 @@ func (t *transportAdapterTranslator) translateTransportAdapterService(
 		return nil, fmt.Errorf("deployment port must be specified for MCPServer %s", server.Name)
 	}
 
-	appProtocol := makePtr(kgatewayMcpAppProtocol)
-	if disableKgatewayMcpAppProtocol == "true" {
-		appProtocol = nil
+	appProtocol := t.defaults.appProtocol()
+	if server.Spec.TransportType == v1alpha1.TransportTypeStdio {
+		backend, err := t.adapterBackend(server)
+		if err != nil {
+			return nil, err
+		}
+		appProtocol = backend.AppProtocol(t.defaults)
 	}
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      server.Name,
 			Namespace: server.Namespace,
+			Labels:    selectorLabels(server),
 		},
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Service",
@@ -512,7 +887,7 @@ func (t *transportAdapterTranslator) translateTransportAdapterService(
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{{
-				Name:     "http",
+				Name:     httpPortName,
 				Protocol: "TCP",
 				Port:     int32(port),
 				TargetPort: intstr.IntOrString{
@@ -520,36 +895,57 @@ func (t *transportAdapterTranslator) translateTransportAdapterService(
 				},
 				AppProtocol: appProtocol,
 			}},
-			Selector: map[string]string{
-				"app.kubernetes.io/name":     server.Name,
-				"app.kubernetes.io/instance": server.Name,
-			},
+			Selector: selectorLabels(server),
 		},
 	}
+	if metrics := serverMetrics(server); metrics != nil && server.Spec.TransportType == v1alpha1.TransportTypeStdio {
+		service.Spec.Ports = append(service.Spec.Ports, metricsServicePort(metrics))
+	}
+	if err := CheckService(server); err != nil {
+		return nil, err
+	}
+	applyServiceSettings(server, service)
 
 	return service, controllerutil.SetOwnerReference(server, service, t.scheme)
 }
 
-func (t *transportAdapterTranslator) translateTransportAdapterConfigAsYAML(
+// translateTransportAdapterConfigData renders the configuration files stored in the
+// MCPServer ConfigMap. Stdio servers use the files of their adapter backend, while HTTP
+// servers keep the agentgateway config.
+func (t *transportAdapterTranslator) translateTransportAdapterConfigData(
 	server *v1alpha1.MCPServer,
-) (string, error) {
+) (map[string]string, error) {
+	if server.Spec.TransportType == v1alpha1.TransportTypeStdio {
+		backend, err := t.adapterBackend(server)
+		if err != nil {
+			return nil, err
+		}
+		data, err := backend.RenderConfig(server, t.translateStdioTarget(server))
+		if err != nil {
+			return nil, fmt.Errorf("failed to translate MCP server config: %w", err)
+		}
+		return data, nil
+	}
+
 	config, err := t.translateTransportAdapterConfig(server)
 	if err != nil {
-		return "", fmt.Errorf("failed to translate MCP server config: %w", err)
+		return nil, fmt.Errorf("failed to translate MCP server config: %w", err)
 	}
 
 	configYaml, err := yaml.Marshal(config)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal MCP server config to YAML: %w", err)
+		return nil, fmt.Errorf("failed to marshal MCP server config to YAML: %w", err)
 	}
 
-	return string(configYaml), nil
+	return map[string]string{
+		"local.yaml": string(configYaml),
+	}, nil
 }
 
 func (t *transportAdapterTranslator) translateTransportAdapterConfigMap(
 	server *v1alpha1.MCPServer,
 ) (*corev1.ConfigMap, error) {
-	configYaml, err := t.translateTransportAdapterConfigAsYAML(server)
+	configData, err := t.translateTransportAdapterConfigData(server)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal MCP server config to YAML: %w", err)
 	}
@@ -563,31 +959,39 @@ func (t *transportAdapterTranslator) translateTransportAdapterConfigMap(
 			Kind:       "ConfigMap",
 			APIVersion: corev1.SchemeGroupVersion.String(),
 		},
-		Data: map[string]string{
-			"local.yaml": configYaml,
-		},
+		Data: configData,
 	}
 
 	return configMap, controllerutil.SetOwnerReference(server, configMap, t.scheme)
 }
 
+// translateStdioTarget returns the stdio process the transport adapter spawns for every session.
+func (t *transportAdapterTranslator) translateStdioTarget(server *v1alpha1.MCPServer) *StdioTargetSpec {
+	if stdioAdapterMode(server) == v1alpha1.StdioAdapterModeSidecar {
+		// The command runs in the MCP server container, the adapter only opens a bridge session.
+		// The environment is provided by the MCP server container.
+		return &StdioTargetSpec{
+			Cmd:  "/bin/sh",
+			Args: []string{"-c", bridgeSessionScript},
+		}
+	}
+	// Only the plain env values are passed explicitly. The envVars and envFrom values are
+	// resolved by the kubelet and inherited by the stdio process from the adapter.
+	return &StdioTargetSpec{
+		Cmd:  server.Spec.Deployment.Cmd,
+		Args: server.Spec.Deployment.Args,
+		Env:  server.Spec.Deployment.Env,
+	}
+}
+
 func (t *transportAdapterTranslator) translateTransportAdapterConfig(server *v1alpha1.MCPServer) (*LocalConfig, error) {
 	mcpTarget := MCPTarget{
 		Name: server.Name,
 	}
 
-	port := server.Spec.Deployment.Port
-	if port == 0 {
-		return nil, fmt.Errorf("deployment port must be specified for MCPServer %s", server.Name)
-	}
-
 	switch server.Spec.TransportType {
 	case v1alpha1.TransportTypeStdio:
-		mcpTarget.Stdio = &StdioTargetSpec{
-			Cmd:  server.Spec.Deployment.Cmd,
-			Args: server.Spec.Deployment.Args,
-			Env:  server.Spec.Deployment.Env,
-		}
+		mcpTarget.Stdio = t.translateStdioTarget(server)
 	case v1alpha1.TransportTypeHTTP:
 		httpTransportConfig := server.Spec.HTTPTransport
 		if httpTransportConfig == nil || httpTransportConfig.TargetPort == 0 {
@@ -602,8 +1006,19 @@ func (t *transportAdapterTranslator) translateTransportAdapterConfig(server *v1a
 		return nil, fmt.Errorf("unsupported transport type: %s", server.Spec.TransportType)
 	}
 
+	return translateLocalConfig(server, mcpTarget)
+}
+
+// translateLocalConfig builds the agentgateway config that serves the given MCP target
+// on the MCPServer port.
+func translateLocalConfig(server *v1alpha1.MCPServer, mcpTarget MCPTarget) (*LocalConfig, error) {
+	port := server.Spec.Deployment.Port
+	if port == 0 {
+		return nil, fmt.Errorf("deployment port must be specified for MCPServer %s", server.Name)
+	}
+
 	config := &LocalConfig{
-		Config: struct{}{},
+		Config: translateRawConfig(server),
 		Binds: []LocalBind{
 			{
 				Port: port,
@@ -641,6 +1056,96 @@ func (t *transportAdapterTranslator) translateTransportAdapterConfig(server *v1a
 	return config, nil
 }
 
+// translateRawConfig translates the observability settings of the MCPServer into the
+// process-level section of the agentgateway config.
+func translateRawConfig(server *v1alpha1.MCPServer) interface{} {
+	observability := server.Spec.Observability
+	if observability == nil || (observability.Tracing == nil && observability.Metrics == nil) {
+		return struct{}{}
+	}
+
+	config := &RawConfig{}
+	if tracing := observability.Tracing; tracing != nil {
+		config.Tracing = &TracingConfig{
+			OTLPEndpoint:       tracing.OTLPEndpoint,
+			RandomSampling:     samplingRatio(tracing),
+			ResourceAttributes: tracingResourceAttributes(server),
+		}
+	}
+	if observability.Metrics != nil {
+		config.StatsAddr = fmt.Sprintf("0.0.0.0:%d", metricsPort(observability.Metrics))
+	}
+	return config
+}
+
+// translateTracingEnvVars returns the OTEL_* environment variables that configure the
+// OpenTelemetry SDK of the MCP server to export spans to the same collector as the
+// transport adapter. Variables set explicitly in the deployment env take precedence.
+func translateTracingEnvVars(server *v1alpha1.MCPServer) []corev1.EnvVar {
+	if server.Spec.Observability == nil || server.Spec.Observability.Tracing == nil {
+		return nil
+	}
+	tracing := server.Spec.Observability.Tracing
+
+	attributes := tracingResourceAttributes(server)
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	resourceAttributes := make([]string, 0, len(keys))
+	for _, key := range keys {
+		resourceAttributes = append(resourceAttributes, key+"="+attributes[key])
+	}
+
+	envVars := []corev1.EnvVar{
+		{Name: "OTEL_SERVICE_NAME", Value: server.Name},
+		{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Value: tracing.OTLPEndpoint},
+		{Name: "OTEL_EXPORTER_OTLP_PROTOCOL", Value: "grpc"},
+		{Name: "OTEL_TRACES_EXPORTER", Value: "otlp"},
+		{Name: "OTEL_TRACES_SAMPLER", Value: "parentbased_traceidratio"},
+		{Name: "OTEL_TRACES_SAMPLER_ARG", Value: samplingRatio(tracing)},
+		{Name: "OTEL_RESOURCE_ATTRIBUTES", Value: strings.Join(resourceAttributes, ",")},
+	}
+
+	userDefined := make(map[string]bool, len(server.Spec.Deployment.Env)+len(server.Spec.Deployment.EnvVars))
+	for name := range server.Spec.Deployment.Env {
+		userDefined[name] = true
+	}
+	for _, envVar := range server.Spec.Deployment.EnvVars {
+		userDefined[envVar.Name] = true
+	}
+	filtered := envVars[:0]
+	for _, envVar := range envVars {
+		if userDefined[envVar.Name] {
+			continue
+		}
+		filtered = append(filtered, envVar)
+	}
+	return filtered
+}
+
+// tracingResourceAttributes returns the OpenTelemetry resource attributes of the MCPServer.
+func tracingResourceAttributes(server *v1alpha1.MCPServer) map[string]string {
+	attributes := map[string]string{
+		"k8s.namespace.name": server.Namespace,
+		"kmcp.mcpserver":     server.Name,
+	}
+	for key, value := range server.Spec.Observability.Tracing.ResourceAttributes {
+		attributes[key] = value
+	}
+	return attributes
+}
+
+// samplingRatio returns the sampling percentage of the tracing settings as a ratio.
+func samplingRatio(tracing *v1alpha1.Tracing) string {
+	percentage := int32(100)
+	if tracing.SamplingPercentage != nil {
+		percentage = *tracing.SamplingPercentage
+	}
+	return strconv.FormatFloat(float64(percentage)/100, 'f', -1, 64)
+}
+
 func (t *transportAdapterTranslator) runPlugins(
 	ctx context.Context,
 	server *v1alpha1.MCPServer,
@@ -660,31 +1165,6 @@ func (t *transportAdapterTranslator) runPlugins(
 	return objects, errs
 }
 
-// validateVersion validates that a version string contains only allowed characters
-// to prevent potential image injection attacks
-func validateVersion(version string) error {
-	if !versionRegex.MatchString(version) {
-		return fmt.Errorf("invalid version format: %s (only alphanumeric characters, dots, and hyphens are allowed)", version)
-	}
-	return nil
-}
-
-// getTransportAdapterImage returns the transport adapter container image,
-// using the environment variable if provided and valid, otherwise using the default
-func getTransportAdapterImage() string {
-	transportAdapterVersion := os.Getenv("TRANSPORT_ADAPTER_VERSION")
-	if transportAdapterVersion == "" {
-		return fmt.Sprintf("%s:%s-musl", transportAdapterRepository, defaultTransportAdapterVersion)
-	}
-
-	if err := validateVersion(transportAdapterVersion); err != nil {
-		klog.Warningf("Invalid TRANSPORT_ADAPTER_VERSION: %v, fallback to %s", err, defaultTransportAdapterVersion)
-		return fmt.Sprintf("%s:%s-musl", transportAdapterRepository, defaultTransportAdapterVersion)
-	}
-
-	return fmt.Sprintf("%s:%s-musl", transportAdapterRepository, transportAdapterVersion)
-}
-
 func makePtr[T any](v T) *T {
 	return &v
 }
@@ -0,0 +1,458 @@
+package transportadapter
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kagent-dev/kmcp/api/v1alpha1"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+func newStdioServer(mode v1alpha1.StdioAdapterMode) *v1alpha1.MCPServer {
+	return &v1alpha1.MCPServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-server",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.MCPServerSpec{
+			TransportType: v1alpha1.TransportTypeStdio,
+			StdioTransport: &v1alpha1.StdioTransport{
+				AdapterMode: mode,
+			},
+			Deployment: v1alpha1.MCPServerDeployment{
+				Image: "test-image:latest",
+				Port:  3000,
+				Cmd:   "npx",
+				Args:  []string{"-y", "@modelcontextprotocol/server-everything"},
+				Env:   map[string]string{"FOO": "bar"},
+				Sidecars: []corev1.Container{{
+					Name:  "user-sidecar",
+					Image: "sidecar:latest",
+				}},
+			},
+		},
+	}
+}
+
+func translateOutputs(t *testing.T, server *v1alpha1.MCPServer, opts ...TranslatorOption) []client.Object {
+	t.Helper()
+	translator := NewTransportAdapterTranslator(newTestScheme(t), nil, opts...)
+	outputs, err := translator.TranslateTransportAdapterOutputs(context.Background(), server)
+	if err != nil {
+		t.Fatalf("failed to translate outputs: %v", err)
+	}
+	return outputs
+}
+
+func findDeployment(t *testing.T, outputs []client.Object) *appsv1.Deployment {
+	t.Helper()
+	for _, output := range outputs {
+		if deployment, ok := output.(*appsv1.Deployment); ok {
+			return deployment
+		}
+	}
+	t.Fatalf("no deployment found in outputs")
+	return nil
+}
+
+func findConfig(t *testing.T, server *v1alpha1.MCPServer) *LocalConfig {
+	t.Helper()
+	translator := &transportAdapterTranslator{scheme: newTestScheme(t)}
+	config, err := translator.translateTransportAdapterConfig(server)
+	if err != nil {
+		t.Fatalf("failed to translate config: %v", err)
+	}
+	return config
+}
+
+func hasVolumeMount(container *corev1.Container, name string) bool {
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStdioCopyBinaryMode(t *testing.T) {
+	for _, mode := range []v1alpha1.StdioAdapterMode{"", v1alpha1.StdioAdapterModeCopyBinary} {
+		t.Run(string(mode), func(t *testing.T) {
+			server := newStdioServer(mode)
+			podSpec := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec
+
+			if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != "copy-binary" {
+				t.Fatalf("expected copy-binary init container, got %v", podSpec.InitContainers)
+			}
+			if len(podSpec.Containers) != 2 {
+				t.Fatalf("expected main container and user sidecar, got %d containers", len(podSpec.Containers))
+			}
+			main := podSpec.Containers[0]
+			if main.Name != "mcp-server" || main.Command[0] != "/adapterbin/agentgateway" {
+				t.Errorf("expected main container to run the copied adapter, got %s %v", main.Name, main.Command)
+			}
+			if findContainer(podSpec.Containers, "transport-adapter") != nil {
+				t.Errorf("unexpected transport-adapter container in CopyBinary mode")
+			}
+
+			stdio := findConfig(t, server).Binds[0].Listeners[0].Routes[0].Backends[0].MCP.Targets[0].Stdio
+			if stdio.Cmd != "npx" {
+				t.Errorf("expected stdio target command npx, got %s", stdio.Cmd)
+			}
+		})
+	}
+}
+
+func TestPodNameLabel(t *testing.T) {
+	server := newStdioServer("")
+	server.Spec.Deployment.Labels = map[string]string{
+		v1alpha1.MCPServerNameLabel: "other-server",
+		"team":                      "search",
+	}
+	labels := findDeployment(t, translateOutputs(t, server)).Spec.Template.Labels
+
+	if labels[v1alpha1.MCPServerNameLabel] != server.Name {
+		t.Errorf("expected the name label %s, got %s", server.Name, labels[v1alpha1.MCPServerNameLabel])
+	}
+	if labels["team"] != "search" {
+		t.Errorf("expected the deployment labels on the pods, got %v", labels)
+	}
+}
+
+func TestStdioSidecarMode(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.Deployment.InitContainer = &v1alpha1.InitContainerConfig{
+		SecurityContext: &corev1.SecurityContext{RunAsNonRoot: makePtr(true)},
+	}
+	podSpec := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec
+
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != "copy-binary" {
+		t.Fatalf("expected copy-binary init container, got %v", podSpec.InitContainers)
+	}
+
+	names := make([]string, 0, len(podSpec.Containers))
+	for _, container := range podSpec.Containers {
+		names = append(names, container.Name)
+	}
+	expectedNames := []string{"mcp-server", "transport-adapter", "user-sidecar"}
+	if len(names) != len(expectedNames) {
+		t.Fatalf("expected containers %v, got %v", expectedNames, names)
+	}
+	for i := range names {
+		if names[i] != expectedNames[i] {
+			t.Fatalf("expected containers %v, got %v", expectedNames, names)
+		}
+	}
+
+	main := podSpec.Containers[0]
+	if main.Image != "test-image:latest" {
+		t.Errorf("expected main container to keep the server image, got %s", main.Image)
+	}
+	expectedCommand := []string{
+		"/bin/sh", "-c", bridgeServeScript, "kmcp-bridge",
+		"npx", "-y", "@modelcontextprotocol/server-everything",
+	}
+	if len(main.Command) != len(expectedCommand) {
+		t.Fatalf("expected main command %v, got %v", expectedCommand, main.Command)
+	}
+	for i := range expectedCommand {
+		if main.Command[i] != expectedCommand[i] {
+			t.Errorf("command index %d: got %q, want %q", i, main.Command[i], expectedCommand[i])
+		}
+	}
+	if len(main.Args) != 0 {
+		t.Errorf("expected no args on main container, got %v", main.Args)
+	}
+	if hasVolumeMount(&main, "binary") {
+		t.Errorf("main container should not mount the adapter binary in Sidecar mode")
+	}
+	if !hasVolumeMount(&main, "bridge") {
+		t.Errorf("main container should mount the bridge volume")
+	}
+	if len(main.Env) != 1 || main.Env[0].Name != "FOO" {
+		t.Errorf("expected main container to keep the server environment, got %v", main.Env)
+	}
+
+	adapter := podSpec.Containers[1]
+	if adapter.Image != defaultBridgeImage {
+		t.Errorf("expected adapter to use the default bridge image, got %s", adapter.Image)
+	}
+	if adapter.Command[0] != "/adapterbin/agentgateway" {
+		t.Errorf("expected adapter to run the copied binary, got %v", adapter.Command)
+	}
+	for _, volume := range []string{"config", "binary", "bridge"} {
+		if !hasVolumeMount(&adapter, volume) {
+			t.Errorf("expected adapter to mount %s", volume)
+		}
+	}
+	if adapter.SecurityContext == nil || adapter.SecurityContext.RunAsNonRoot == nil {
+		t.Errorf("expected adapter to use the init container security context")
+	}
+
+	foundBridgeVolume := false
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == "bridge" && volume.EmptyDir != nil {
+			foundBridgeVolume = true
+		}
+	}
+	if !foundBridgeVolume {
+		t.Errorf("expected bridge emptyDir volume")
+	}
+
+	stdio := findConfig(t, server).Binds[0].Listeners[0].Routes[0].Backends[0].MCP.Targets[0].Stdio
+	if stdio.Cmd != "/bin/sh" || len(stdio.Args) != 2 || stdio.Args[1] != bridgeSessionScript {
+		t.Errorf("expected stdio target to open a bridge session, got %s %v", stdio.Cmd, stdio.Args)
+	}
+}
+
+func TestStdioSidecarModeCustomBridgeImage(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.StdioTransport.BridgeImage = "registry.example.com/busybox:1.37"
+	podSpec := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec
+
+	adapter := findContainer(podSpec.Containers, "transport-adapter")
+	if adapter == nil || adapter.Image != "registry.example.com/busybox:1.37" {
+		t.Errorf("expected adapter to use the custom bridge image, got %v", adapter)
+	}
+}
+
+func TestStdioSidecarModeRequiresCmd(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.Deployment.Cmd = ""
+	translator := NewTransportAdapterTranslator(newTestScheme(t), nil)
+	if _, err := translator.TranslateTransportAdapterOutputs(context.Background(), server); err == nil {
+		t.Errorf("expected error when cmd is empty in Sidecar mode")
+	}
+}
+
+func TestTracingConfig(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	if _, ok := findConfig(t, server).Config.(struct{}); !ok {
+		t.Fatalf("expected an empty config section without observability settings")
+	}
+
+	server.Spec.Deployment.Env = map[string]string{"OTEL_SERVICE_NAME": "custom"}
+	server.Spec.Observability = &v1alpha1.Observability{
+		Tracing: &v1alpha1.Tracing{
+			OTLPEndpoint:       "http://otel-collector:4317",
+			SamplingPercentage: makePtr(int32(25)),
+			ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+		},
+	}
+
+	raw, ok := findConfig(t, server).Config.(*RawConfig)
+	if !ok || raw.Tracing == nil {
+		t.Fatalf("expected tracing to be configured")
+	}
+	if raw.Tracing.OTLPEndpoint != "http://otel-collector:4317" || raw.Tracing.RandomSampling != "0.25" {
+		t.Errorf("unexpected tracing config: %+v", raw.Tracing)
+	}
+	if raw.Tracing.ResourceAttributes["deployment.environment"] != "prod" {
+		t.Errorf("expected the resource attributes to be rendered, got %v", raw.Tracing.ResourceAttributes)
+	}
+
+	env := map[string]string{}
+	for _, envVar := range findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec.Containers[0].Env {
+		env[envVar.Name] = envVar.Value
+	}
+	if env["OTEL_EXPORTER_OTLP_ENDPOINT"] != "http://otel-collector:4317" || env["OTEL_TRACES_SAMPLER_ARG"] != "0.25" {
+		t.Errorf("unexpected OTEL env vars: %v", env)
+	}
+	resourceAttributes := "deployment.environment=prod,k8s.namespace.name=default,kmcp.mcpserver=test-server"
+	if env["OTEL_RESOURCE_ATTRIBUTES"] != resourceAttributes {
+		t.Errorf("unexpected resource attributes: %s", env["OTEL_RESOURCE_ATTRIBUTES"])
+	}
+	// Variables set by the user take precedence
+	if env["OTEL_SERVICE_NAME"] != "custom" {
+		t.Errorf("expected the user defined service name to be kept, got %s", env["OTEL_SERVICE_NAME"])
+	}
+}
+
+func TestMetricsMonitor(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeSidecar)
+	server.Spec.Observability = &v1alpha1.Observability{
+		Metrics: &v1alpha1.Metrics{
+			Port:     15020,
+			Monitor:  v1alpha1.MetricsMonitorKindPodMonitor,
+			Interval: "30s",
+		},
+	}
+
+	raw, ok := findConfig(t, server).Config.(*RawConfig)
+	if !ok || raw.StatsAddr != "0.0.0.0:15020" {
+		t.Fatalf("expected the adapter to serve metrics on port 15020, got %+v", findConfig(t, server).Config)
+	}
+
+	// Without the Prometheus Operator CRDs only the metrics port is exposed
+	outputs := translateOutputs(t, server)
+	for _, output := range outputs {
+		if _, ok := output.(*unstructured.Unstructured); ok {
+			t.Fatalf("expected no monitor without the Prometheus Operator CRDs")
+		}
+	}
+	adapter := findDeployment(t, outputs).Spec.Template.Spec.Containers[1]
+	if len(adapter.Ports) != 1 || adapter.Ports[0].Name != "metrics" || adapter.Ports[0].ContainerPort != 15020 {
+		t.Errorf("expected the adapter container to declare the metrics port, got %+v", adapter.Ports)
+	}
+	for _, output := range outputs {
+		if service, ok := output.(*corev1.Service); ok && len(service.Spec.Ports) != 2 {
+			t.Errorf("expected the service to expose the metrics port, got %+v", service.Spec.Ports)
+		}
+	}
+
+	translator := NewTransportAdapterTranslator(
+		newTestScheme(t),
+		nil,
+		WithMonitoring(map[string]string{"release": "prometheus"}, MonitorKinds()...),
+	)
+	outputs, err := translator.TranslateTransportAdapterOutputs(context.Background(), server)
+	if err != nil {
+		t.Fatalf("failed to translate outputs: %v", err)
+	}
+	var monitor *unstructured.Unstructured
+	for _, output := range outputs {
+		if u, ok := output.(*unstructured.Unstructured); ok {
+			monitor = u
+		}
+	}
+	if monitor == nil || monitor.GetKind() != "PodMonitor" {
+		t.Fatalf("expected a PodMonitor, got %v", monitor)
+	}
+	if monitor.GetLabels()["release"] != "prometheus" {
+		t.Errorf("expected the monitor labels to be applied, got %v", monitor.GetLabels())
+	}
+	endpoints, _, _ := unstructured.NestedSlice(monitor.Object, "spec", "podMetricsEndpoints")
+	if len(endpoints) != 1 || endpoints[0].(map[string]interface{})["interval"] != "30s" {
+		t.Errorf("unexpected pod metrics endpoints: %v", endpoints)
+	}
+	if len(monitor.GetOwnerReferences()) != 1 {
+		t.Errorf("expected the monitor to be owned by the MCPServer")
+	}
+}
+
+func TestTypedEnvVars(t *testing.T) {
+	server := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	server.Spec.Deployment.Env = map[string]string{"LOG_LEVEL": "debug"}
+	server.Spec.Deployment.EnvVars = []corev1.EnvVar{
+		{
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+			},
+		},
+		{
+			Name: "API_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+					Key:                  "token",
+				},
+			},
+		},
+	}
+	server.Spec.Deployment.EnvFrom = []corev1.EnvFromSource{{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+		},
+	}}
+
+	container := findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec.Containers[0]
+	if len(container.Env) != 3 || container.Env[0].Name != "LOG_LEVEL" {
+		t.Fatalf("expected the plain values followed by the typed variables, got %+v", container.Env)
+	}
+	if container.Env[1].ValueFrom == nil || container.Env[1].ValueFrom.FieldRef == nil {
+		t.Errorf("expected the downward API reference to be kept, got %+v", container.Env[1])
+	}
+	if len(container.EnvFrom) != 1 || container.EnvFrom[0].ConfigMapRef.Name != "settings" {
+		t.Errorf("expected the ConfigMap to be referenced, got %+v", container.EnvFrom)
+	}
+
+	// The stdio process inherits the resolved values instead of receiving unresolved references
+	target := findConfig(t, server).Binds[0].Listeners[0].Routes[0].Backends[0].MCP.Targets[0].Stdio
+	if _, ok := target.Env["API_TOKEN"]; ok || target.Env["LOG_LEVEL"] != "debug" {
+		t.Errorf("unexpected stdio target env: %v", target.Env)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	server := &v1alpha1.MCPServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "http-server",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.MCPServerSpec{
+			TransportType: v1alpha1.TransportTypeHTTP,
+			HTTPTransport: &v1alpha1.HTTPTransport{
+				TargetPort: 8080,
+				TargetPath: "/mcp",
+			},
+			Deployment: v1alpha1.MCPServerDeployment{
+				Image: "http-image:latest",
+				Port:  8080,
+			},
+		},
+	}
+
+	deployment := findDeployment(t, translateOutputs(t, server))
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.ReadinessProbe == nil || container.ReadinessProbe.HTTPGet == nil ||
+		container.ReadinessProbe.HTTPGet.Path != "/mcp" || container.ReadinessProbe.HTTPGet.Port.IntValue() != 8080 {
+		t.Errorf("expected an HTTP readiness probe on the target path, got %+v", container.ReadinessProbe)
+	}
+	if container.StartupProbe == nil || container.StartupProbe.FailureThreshold != startupProbeFailureThreshold {
+		t.Errorf("expected a startup probe tolerating slow installs, got %+v", container.StartupProbe)
+	}
+	defaultHash := deployment.Spec.Template.Annotations["kmcp.kagent.dev/mcpserver-config-hash"]
+
+	server.Spec.Deployment.Probes = &v1alpha1.Probes{
+		Liveness: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt32(9090)},
+			},
+		},
+	}
+	deployment = findDeployment(t, translateOutputs(t, server))
+	container = deployment.Spec.Template.Spec.Containers[0]
+	if container.LivenessProbe.HTTPGet == nil || container.LivenessProbe.HTTPGet.Path != "/healthz" {
+		t.Errorf("expected the liveness probe to be overridden, got %+v", container.LivenessProbe)
+	}
+	if container.ReadinessProbe == nil {
+		t.Errorf("expected the default readiness probe to be kept")
+	}
+	if deployment.Spec.Template.Annotations["kmcp.kagent.dev/mcpserver-config-hash"] == defaultHash {
+		t.Errorf("expected the probe settings to change the pod template hash")
+	}
+
+	server.Spec.Deployment.Probes = &v1alpha1.Probes{ReadinessPath: "/healthz"}
+	container = findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec.Containers[0]
+	if container.ReadinessProbe.HTTPGet == nil || container.ReadinessProbe.HTTPGet.Path != "/healthz" {
+		t.Errorf("expected the readiness probe on the configured path, got %+v", container.ReadinessProbe)
+	}
+
+	stdioServer := newStdioServer(v1alpha1.StdioAdapterModeCopyBinary)
+	container = findDeployment(t, translateOutputs(t, stdioServer)).Spec.Template.Spec.Containers[0]
+	if container.StartupProbe != nil {
+		t.Errorf("expected no startup probe for the transport adapter, got %+v", container.StartupProbe)
+	}
+
+	server.Spec.Deployment.Probes = &v1alpha1.Probes{Disabled: true}
+	container = findDeployment(t, translateOutputs(t, server)).Spec.Template.Spec.Containers[0]
+	if container.LivenessProbe != nil || container.ReadinessProbe != nil || container.StartupProbe != nil {
+		t.Errorf("expected no probes when disabled")
+	}
+}